@@ -16,6 +16,18 @@ var (
 	utf8BOM = []byte{0xef, 0xbb, 0xbf}
 )
 
+// DetectEncoding names the character encoding that a file appears to be in.
+// Like readFileAsUTF8String, it falls back to UTF-8 when the encoding
+// can't be determined with certainty. Submissions are uploaded as they are,
+// so this is for information only.
+func DetectEncoding(b []byte) string {
+	_, name, certain := charset.DetermineEncoding(b, mimeType)
+	if !certain {
+		return "utf-8"
+	}
+	return name
+}
+
 func readFileAsUTF8String(filename string) (*string, error) {
 	b, err := ioutil.ReadFile(filename)
 	if err != nil {
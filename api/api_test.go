@@ -0,0 +1,25 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		contents []byte
+		expected string
+	}{
+		{"plain ASCII", []byte("hello"), "utf-8"},
+		{"UTF-8 with a BOM", []byte("\xef\xbb\xbfhello"), "utf-8"},
+		{"UTF-16 with a BOM", []byte("\xff\xfeh\x00i\x00"), "utf-16le"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DetectEncoding(tc.contents))
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/exercism/cli/debug"
+)
+
+const (
+	// CassetteEnv names the environment variable that points to a cassette file.
+	// When it is set, HTTP calls are recorded to, or replayed from, the cassette.
+	CassetteEnv = "EXERCISM_CASSETTE"
+	// CassetteModeEnv names the environment variable that picks the cassette mode.
+	// It is either record or replay. The default is replay.
+	CassetteModeEnv = "EXERCISM_CASSETTE_MODE"
+)
+
+// Cassette modes.
+const (
+	// CassetteRecord makes real requests, and writes them to the cassette.
+	CassetteRecord = "record"
+	// CassetteReplay answers requests from the cassette, without touching the network.
+	CassetteReplay = "replay"
+)
+
+// Cassette holds recorded HTTP interactions.
+// Requests are matched on their method, path, query, and body.
+// The host is ignored, so a cassette recorded against one server
+// can be replayed with a different base URL.
+type Cassette struct {
+	mu   sync.Mutex
+	path string
+	mode string
+	// Interactions are kept in the order they were recorded.
+	Interactions []*Interaction `json:"interactions"`
+	// played marks the interactions that have been replayed.
+	played []bool
+}
+
+// Interaction is a single request and the response it got.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is the part of a request that is used to match it.
+type CassetteRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// CassetteResponse is a recorded response.
+// Bodies that aren't valid UTF-8 are base64 encoded.
+// Headers that carry credentials are redacted, and so are the credentials in JSON bodies.
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Base64     bool        `json:"base64,omitempty"`
+}
+
+// OpenCassette prepares a cassette in the given mode.
+// Recording adds to the interactions already in the file, if there is one,
+// so that several commands can be recorded in the same cassette.
+// Replaying reads the file.
+func OpenCassette(path, mode string) (*Cassette, error) {
+	c := &Cassette{path: path, mode: mode, Interactions: []*Interaction{}}
+	switch mode {
+	case CassetteRecord:
+		if err := c.load(); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return c, nil
+	case CassetteReplay:
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+		c.played = make([]bool, len(c.Interactions))
+		return c, nil
+	default:
+		return nil, fmt.Errorf("unknown cassette mode '%s', use %s or %s", mode, CassetteRecord, CassetteReplay)
+	}
+}
+
+// load reads the interactions in the cassette file.
+func (c *Cassette) load() error {
+	b, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, c); err != nil {
+		return fmt.Errorf("unable to read cassette %s - %s", c.path, err)
+	}
+	return nil
+}
+
+// CassetteFromEnv opens the cassette configured in the environment.
+// It returns nil if no cassette is configured.
+func CassetteFromEnv() (*Cassette, error) {
+	path := os.Getenv(CassetteEnv)
+	if path == "" {
+		return nil, nil
+	}
+	mode := os.Getenv(CassetteModeEnv)
+	if mode == "" {
+		mode = CassetteReplay
+	}
+	return OpenCassette(path, mode)
+}
+
+// Transport records requests made through the next transport,
+// or replays them, depending on the mode of the cassette.
+// If next is nil, http.DefaultTransport is used.
+func (c *Cassette) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &cassetteTransport{cassette: c, next: next}
+}
+
+// Save writes the cassette to its file.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save()
+}
+
+func (c *Cassette) save() error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, append(b, '\n'), os.FileMode(0644))
+}
+
+// record adds an interaction and saves the cassette right away,
+// so that nothing is lost if the command exits early.
+func (c *Cassette) record(interaction *Interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Interactions = append(c.Interactions, interaction)
+	return c.save()
+}
+
+// find returns the first matching interaction that hasn't been replayed yet.
+// Once all matches have been replayed, the last one is used again,
+// so that a request can be repeated any number of times.
+func (c *Cassette) find(req CassetteRequest) (*Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var last *Interaction
+	for i, interaction := range c.Interactions {
+		if interaction.Request != req {
+			continue
+		}
+		if !c.played[i] {
+			c.played[i] = true
+			return interaction, true
+		}
+		last = interaction
+	}
+	return last, last != nil
+}
+
+type cassetteTransport struct {
+	cassette *Cassette
+	next     http.RoundTripper
+}
+
+// RoundTrip records or replays a request.
+func (ct *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	// Credentials are never recorded, so they are left out when matching too.
+	normalized := debug.RedactBody(req.Header, []byte(normalizeBody(req.Header.Get("Content-Type"), body)))
+	key := CassetteRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+		Body:   string(normalized),
+	}
+
+	if ct.cassette.mode == CassetteReplay {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		interaction, ok := ct.cassette.find(key)
+		if !ok {
+			return nil, fmt.Errorf("no recorded response for %s %s in cassette %s", req.Method, req.URL.RequestURI(), ct.cassette.path)
+		}
+		return interaction.Response.response(req)
+	}
+
+	// Don't change the caller's request.
+	r := new(http.Request)
+	*r = *req
+	if body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	res, err := ct.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	// Cassettes end up in fixtures, so credentials, e.g. cookies or tokens, are never recorded.
+	recorded := CassetteResponse{StatusCode: res.StatusCode, Header: debug.RedactHeader(res.Header)}
+	if utf8.Valid(resBody) {
+		recorded.Body = string(debug.RedactBody(res.Header, resBody))
+	} else {
+		recorded.Body = base64.StdEncoding.EncodeToString(resBody)
+		recorded.Base64 = true
+	}
+	if err := ct.cassette.record(&Interaction{Request: key, Response: recorded}); err != nil {
+		return nil, fmt.Errorf("unable to write cassette %s - %s", ct.cassette.path, err)
+	}
+	return res, nil
+}
+
+// response rebuilds the recorded response for a request.
+func (cr CassetteResponse) response(req *http.Request) (*http.Response, error) {
+	body := []byte(cr.Body)
+	if cr.Base64 {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(cr.Body); err != nil {
+			return nil, err
+		}
+	}
+	header := cr.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cr.StatusCode, http.StatusText(cr.StatusCode)),
+		StatusCode:    cr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBody reads the body without using it up.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// normalizeBody makes bodies comparable.
+// JSON is re-encoded with sorted keys, and multipart forms
+// are described without their random boundary.
+func normalizeBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if s, err := normalizeMultipart(body, params["boundary"]); err == nil {
+			return s
+		}
+	case mediaType == "application/json":
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			if b, err := json.Marshal(v); err == nil {
+				return string(b)
+			}
+		}
+	}
+	return string(body)
+}
+
+func normalizeMultipart(body []byte, boundary string) (string, error) {
+	var b bytes.Buffer
+	r := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "--%s %s\n%s\n", part.FormName(), part.FileName(), content)
+	}
+}
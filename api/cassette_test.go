@@ -0,0 +1,186 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	var uploads []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solutions/latest":
+			fmt.Fprintf(w, `{"solution": {"id": "%s-id"}}`, r.URL.Query().Get("exercise_id"))
+		case "/solutions/bogus-id":
+			file, _, err := r.FormFile("files[]")
+			assert.NoError(t, err)
+			b, err := ioutil.ReadAll(file)
+			assert.NoError(t, err)
+			uploads = append(uploads, string(b))
+			w.WriteHeader(http.StatusCreated)
+		case "/files/binary":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "1a11111aaaa111aa1a11111a11111aa1"})
+			w.Write([]byte{0xff, 0x00, 0xfe})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	run := func(c *Cassette, baseURL string) {
+		client, err := NewClient("abc123", baseURL)
+		assert.NoError(t, err)
+		client.Client = &http.Client{Transport: c.Transport(nil)}
+
+		solution, err := client.GetLatestSolution("", "bogus")
+		assert.NoError(t, err)
+		assert.Equal(t, "bogus-id", solution.ID)
+
+		solution, err = client.GetLatestSolution("", "other")
+		assert.NoError(t, err)
+		assert.Equal(t, "other-id", solution.ID)
+
+		files := []SolutionFile{{Name: "file.txt", Content: strings.NewReader("hello")}}
+		assert.NoError(t, client.UpdateSolution("bogus-id", files))
+
+		res, err := client.Client.Get(baseURL + "/files/binary")
+		assert.NoError(t, err)
+		b, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0xff, 0x00, 0xfe}, b)
+	}
+
+	recorder, err := OpenCassette(path, CassetteRecord)
+	assert.NoError(t, err)
+	run(recorder, ts.URL)
+	ts.Close()
+	assert.Equal(t, []string{"hello"}, uploads)
+
+	// Cookies aren't recorded.
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "Set-Cookie")
+	assert.NotContains(t, string(b), "1a11111aaaa111aa1a11111a11111aa1")
+
+	// The server is gone, and the base URL is different.
+	player, err := OpenCassette(path, CassetteReplay)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(player.Interactions))
+	run(player, "http://example.com")
+	assert.Equal(t, []string{"hello"}, uploads)
+}
+
+func TestCassetteReplayMatchesRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	cassette := `{"interactions": [
+		{
+			"request": {"method": "POST", "path": "/things", "query": "a=1&b=2", "body": "{\"x\":1,\"y\":2}"},
+			"response": {"status_code": 200, "body": "first"}
+		},
+		{
+			"request": {"method": "POST", "path": "/things", "query": "a=1&b=2", "body": "{\"x\":1,\"y\":2}"},
+			"response": {"status_code": 200, "body": "second"}
+		}
+	]}`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(cassette), os.FileMode(0644)))
+
+	c, err := OpenCassette(path, CassetteReplay)
+	assert.NoError(t, err)
+	client := &http.Client{Transport: c.Transport(nil)}
+
+	post := func(query, body string) (string, error) {
+		req, err := http.NewRequest("POST", "http://example.com/things?"+query, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		b, err := ioutil.ReadAll(res.Body)
+		return string(b), err
+	}
+
+	// The query and the JSON body are compared after normalizing them.
+	// Interactions are replayed in order, and the last one is repeated.
+	for _, expected := range []string{"first", "second", "second"} {
+		body, err := post("b=2&a=1", `{"y": 2, "x": 1}`)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, body)
+	}
+
+	_, err = post("a=1&b=2", `{"x": 2}`)
+	assert.Error(t, err)
+	_, err = post("a=1", `{"x": 1, "y": 2}`)
+	assert.Error(t, err)
+}
+
+func TestCassetteRecordAddsToTheCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"path": "%s", "token": "1a11111aaaa111aa1a11111a11111aa1"}`, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	// Each command records with its own cassette.
+	for _, endpoint := range []string{"/first", "/second"} {
+		recorder, err := OpenCassette(path, CassetteRecord)
+		assert.NoError(t, err)
+		client := &http.Client{Transport: recorder.Transport(nil)}
+		req, err := http.NewRequest("POST", ts.URL+endpoint, strings.NewReader(`{"token": "1a11111aaaa111aa1a11111a11111aa1"}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+	}
+
+	// Tokens in JSON bodies aren't recorded.
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "1a11111aaaa111aa1a11111a11111aa1")
+
+	player, err := OpenCassette(path, CassetteReplay)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(player.Interactions)) {
+		assert.Equal(t, "/first", player.Interactions[0].Request.Path)
+		assert.Equal(t, "/second", player.Interactions[1].Request.Path)
+	}
+
+	// Requests still match their redacted recordings.
+	client := &http.Client{Transport: player.Transport(nil)}
+	req, err := http.NewRequest("POST", "http://example.com/second", strings.NewReader(`{"token": "1a11111aaaa111aa1a11111a11111aa1"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if assert.NoError(t, err) {
+		b, err = ioutil.ReadAll(res.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), `"path":"/second"`)
+	}
+}
+
+func TestOpenCassetteUnknownMode(t *testing.T) {
+	_, err := OpenCassette("cassette.json", "rewind")
+	assert.Error(t, err)
+}
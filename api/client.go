@@ -1,9 +1,14 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/exercism/cli/debug"
@@ -16,14 +21,49 @@ var (
 
 	// DefaultHTTPClient configures a timeout to use by default.
 	DefaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+	// DefaultRetryPolicy is the retry policy used by new clients.
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+	}
+
+	// sleep is swapped out in tests.
+	sleep = time.Sleep
 )
 
+// RetryPolicy determines how a client retries failed requests.
+// Requests are retried when they fail to get a response at all,
+// when the API is rate limiting us (429), or when the API has a
+// temporary problem (5xx).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is how long to wait before the first retry.
+	// The delay doubles with each attempt, plus some random jitter.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts.
+	// If the API asks us to wait longer than this, we give up.
+	MaxDelay time.Duration
+	// RetryNonIdempotent allows retrying requests such as POST and PATCH.
+	// Only opt in if the request is safe to send more than once.
+	RetryNonIdempotent bool
+}
+
 // Client is an http client that is configured for Exercism.
 type Client struct {
 	*http.Client
 	ContentType string
 	Token       string
 	APIBaseURL  string
+	// Retry configures how failed requests are retried.
+	Retry RetryPolicy
+	// Timeout overrides the timeout of the underlying http client, if set.
+	Timeout time.Duration
+	// Context cancels the client's requests, including any retries.
+	// If it is nil, requests can't be cancelled.
+	Context context.Context
 }
 
 // NewClient returns an Exercism API client.
@@ -32,6 +72,7 @@ func NewClient(token, baseURL string) (*Client, error) {
 		Client:     DefaultHTTPClient,
 		Token:      token,
 		APIBaseURL: baseURL,
+		Retry:      DefaultRetryPolicy,
 	}, nil
 }
 
@@ -45,6 +86,9 @@ func (c *Client) NewRequest(method, url string, body io.Reader) (*http.Request,
 	if err != nil {
 		return nil, err
 	}
+	if c.Context != nil {
+		req = req.WithContext(c.Context)
+	}
 
 	req.Header.Set("User-Agent", UserAgent)
 	if c.ContentType == "" {
@@ -60,42 +104,206 @@ func (c *Client) NewRequest(method, url string, body io.Reader) (*http.Request,
 }
 
 // Do performs an http.Request and optionally parses the response body into the given interface.
+// Failed requests are retried according to the client's retry policy.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
-	debug.DumpRequest(req)
+	attempts := c.Retry.MaxAttempts
+	if attempts < 1 || !c.Retry.allows(req.Method) {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.Body != nil {
+			// The previous attempt consumed the body, so we need a fresh copy.
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		debug.DumpRequest(req)
+
+		res, err := c.httpClient().Do(req)
+		if err != nil && req.Context().Err() != nil {
+			// The request was cancelled, there's no point in retrying.
+			return nil, req.Context().Err()
+		}
+
+		delay, retry := c.Retry.next(attempt, res, err)
+		if attempt >= attempts || !retry || (req.Body != nil && req.GetBody == nil) {
+			if err != nil {
+				return nil, err
+			}
+			debug.DumpResponse(res)
+			return res, nil
+		}
+
+		if err != nil {
+			debug.Printf("Request failed: %s\n", err)
+		} else {
+			debug.Printf("Request failed: %s\n", res.Status)
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		debug.Printf("Retrying %s %s in %s (attempt %d of %d)\n", req.Method, req.URL, delay, attempt+1, attempts)
+		if err := wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait pauses before a retry. It stops early if the context is cancelled.
+func wait(ctx context.Context, delay time.Duration) error {
+	if ctx.Done() == nil {
+		// The context can't be cancelled.
+		sleep(delay)
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.Client == nil {
+		c.Client = DefaultHTTPClient
+	}
+	if c.Timeout == 0 || c.Timeout == c.Client.Timeout {
+		return c.Client
+	}
+	hc := *c.Client
+	hc.Timeout = c.Timeout
+	return &hc
+}
+
+// allows checks whether requests with the given method may be retried.
+func (p RetryPolicy) allows(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE", "TRACE":
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// next decides whether a request should be tried again, and how long to wait first.
+func (p RetryPolicy) next(attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if err == nil && res.StatusCode != http.StatusTooManyRequests && res.StatusCode < 500 {
+		return 0, false
+	}
+
+	delay := p.backoff(attempt)
+	if res != nil {
+		if wait, ok := retryAfter(res); ok {
+			if wait > p.MaxDelay {
+				return 0, false
+			}
+			if wait > delay {
+				delay = wait
+			}
+		}
+	}
+	return delay, true
+}
+
+// backoff is how long to wait after the given attempt failed.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay > p.MaxDelay || delay < p.BaseDelay {
+		delay = p.MaxDelay
+	}
+	// Add jitter so that clients don't retry in lockstep.
+	if delay > 0 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+	return delay
+}
 
-	res, err := c.Client.Do(req)
+// Backoff waits before another try, after the given attempt failed.
+// It follows the client's retry policy, and is meant for work that spans
+// more than a single request, like reading a response body.
+// It stops early if the client's context is cancelled.
+func (c *Client) Backoff(attempt int) error {
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return wait(ctx, c.Retry.backoff(attempt))
+}
+
+// retryAfter reads the Retry-After header,
+// which is either a number of seconds or a date.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+// decode performs the request and parses the response body into v.
+// If the API responds with an error, it is returned as an *Error.
+func (c *Client) decode(req *http.Request, v interface{}) error {
+	res, err := c.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer res.Body.Close()
 
-	debug.DumpResponse(res)
-	return res, nil
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newError(res)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("unable to parse API response - %s", err)
+	}
+	return nil
 }
 
-// TokenIsValid calls the API to determine whether the token is valid.
-func (c *Client) TokenIsValid() (bool, error) {
-	url := fmt.Sprintf("%s/validate_token", c.APIBaseURL)
-	req, err := c.NewRequest("GET", url, nil)
+// ValidateToken calls the API to check that the token is valid.
+func (c *Client) ValidateToken() error {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s/validate_token", c.APIBaseURL), nil)
 	if err != nil {
-		return false, err
+		return err
 	}
-	resp, err := c.Do(req)
+	return c.decode(req, nil)
+}
+
+// Ping calls the API /ping to check that the API can be reached.
+func (c *Client) Ping() error {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s/ping", c.APIBaseURL), nil)
 	if err != nil {
-		return false, err
+		return err
 	}
-	return resp.StatusCode == http.StatusOK, nil
+	return c.decode(req, nil)
+}
+
+// TokenIsValid calls the API to determine whether the token is valid.
+func (c *Client) TokenIsValid() (bool, error) {
+	err := c.ValidateToken()
+	if _, ok := err.(*Error); ok {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // IsPingable calls the API /ping to determine whether the API can be reached.
 func (c *Client) IsPingable() (bool, error) {
-	url := fmt.Sprintf("%s/ping", c.APIBaseURL)
-	req, err := c.NewRequest("GET", url, nil)
-	if err != nil {
-		return false, err
-	}
-	resp, err := c.Do(req)
-	if err != nil {
-		return false, err
+	err := c.Ping()
+	if _, ok := err.(*Error); ok {
+		return false, nil
 	}
-	return resp.StatusCode == http.StatusOK, nil
+	return err == nil, err
 }
@@ -1,11 +1,16 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -79,3 +84,221 @@ func TestDo(t *testing.T) {
 
 	assert.Equal(t, "world", body.Hello)
 }
+
+func TestDoRetries(t *testing.T) {
+	oldSleep := sleep
+	var delays []time.Duration
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	defer func() { sleep = oldSleep }()
+
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+	}
+
+	testCases := []struct {
+		desc     string
+		method   string
+		policy   RetryPolicy
+		statuses []int
+		header   string
+		calls    int
+		status   int
+	}{
+		{
+			desc:     "retries server errors",
+			method:   "GET",
+			policy:   policy,
+			statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			calls:    3,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "gives up after the last attempt",
+			method:   "GET",
+			policy:   policy,
+			statuses: []int{http.StatusInternalServerError},
+			calls:    3,
+			status:   http.StatusInternalServerError,
+		},
+		{
+			desc:     "does not retry client errors",
+			method:   "GET",
+			policy:   policy,
+			statuses: []int{http.StatusNotFound},
+			calls:    1,
+			status:   http.StatusNotFound,
+		},
+		{
+			desc:     "does not retry non-idempotent requests by default",
+			method:   "PATCH",
+			policy:   policy,
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			calls:    1,
+			status:   http.StatusServiceUnavailable,
+		},
+		{
+			desc:     "retries non-idempotent requests when asked to",
+			method:   "PATCH",
+			policy:   RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute, RetryNonIdempotent: true},
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			calls:    2,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "gives up if asked to wait too long",
+			method:   "GET",
+			policy:   policy,
+			statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			header:   "3600",
+			calls:    1,
+			status:   http.StatusTooManyRequests,
+		},
+		{
+			desc:     "no retries without a policy",
+			method:   "GET",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			calls:    1,
+			status:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var calls int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, "the body", string(b))
+
+				status := tc.statuses[len(tc.statuses)-1]
+				if calls < len(tc.statuses) {
+					status = tc.statuses[calls]
+				}
+				calls++
+				if tc.header != "" {
+					w.Header().Set("Retry-After", tc.header)
+				}
+				w.WriteHeader(status)
+			}))
+			defer ts.Close()
+
+			client := &Client{Retry: tc.policy}
+			req, err := client.NewRequest(tc.method, ts.URL, bytes.NewBufferString("the body"))
+			assert.NoError(t, err)
+
+			res, err := client.Do(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, res.StatusCode)
+			assert.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+
+	// Backoff doubles with each attempt, with up to half of it taken off as jitter.
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second} {
+		delay, ok := policy.next(attempt+1, unavailable, nil)
+		assert.True(t, ok)
+		assert.True(t, delay >= max/2 && delay <= max, fmt.Sprintf("attempt %d: %s", attempt+1, delay))
+	}
+
+	// Retry-After can be given in seconds.
+	limited := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	limited.Header.Set("Retry-After", "5")
+	delay, ok := policy.next(1, limited, nil)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+
+	// Retry-After can be given as a date.
+	limited.Header.Set("Retry-After", time.Now().Add(8*time.Second).UTC().Format(http.TimeFormat))
+	delay, ok = policy.next(1, limited, nil)
+	assert.True(t, ok)
+	assert.True(t, delay > 6*time.Second && delay <= 8*time.Second, delay.String())
+
+	// Network errors are retried.
+	_, ok = policy.next(1, nil, errors.New("connection reset by peer"))
+	assert.True(t, ok)
+
+	// Successful responses are not.
+	_, ok = policy.next(1, &http.Response{StatusCode: http.StatusOK}, nil)
+	assert.False(t, ok)
+}
+
+func TestClientTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	client := &Client{Timeout: 10 * time.Millisecond}
+	req, err := client.NewRequest("GET", ts.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.Error(t, err)
+
+	// The shared client is left alone.
+	assert.Equal(t, 10*time.Second, DefaultHTTPClient.Timeout)
+}
+
+func TestClientContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		// Cancel while the client waits to retry.
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := &Client{
+		Context: ctx,
+		Retry:   RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour},
+	}
+	req, err := client.NewRequest("GET", ts.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, calls)
+
+	// Requests made after the context is cancelled fail right away.
+	req, err = client.NewRequest("GET", ts.URL, nil)
+	assert.NoError(t, err)
+	_, err = client.Do(req)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestValidateToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("good", ts.URL)
+	assert.NoError(t, err)
+	assert.NoError(t, client.ValidateToken())
+
+	client, err = NewClient("bad", ts.URL)
+	assert.NoError(t, err)
+	err = client.ValidateToken()
+	apiErr, ok := err.(*Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	}
+
+	ok, err = client.TokenIsValid()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is an error reported by the Exercism API.
+type Error struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int `json:"-"`
+	// Type identifies the kind of error, e.g. track_ambiguous.
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	// PossibleTrackIDs lists the candidates when an exercise
+	// exists on more than one track.
+	PossibleTrackIDs []string `json:"possible_track_ids"`
+}
+
+func (e *Error) Error() string {
+	if e.Type == "track_ambiguous" && len(e.PossibleTrackIDs) > 0 {
+		return fmt.Sprintf("%s: %s", e.Message, strings.Join(e.PossibleTrackIDs, ", "))
+	}
+	return e.Message
+}
+
+// newError reads the error out of an unsuccessful response.
+// If the body doesn't describe the error, the HTTP status is used instead.
+func newError(res *http.Response) *Error {
+	var payload struct {
+		Error *Error `json:"error"`
+	}
+	apiErr := &Error{}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err == nil && payload.Error != nil {
+		apiErr = payload.Error
+	}
+	apiErr.StatusCode = res.StatusCode
+	if apiErr.Message == "" {
+		apiErr.Message = res.Status
+	}
+	return apiErr
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+)
+
+// Solution is a person's solution to an exercise.
+type Solution struct {
+	ID                  string    `json:"id"`
+	URL                 string    `json:"url"`
+	User                User      `json:"user"`
+	Exercise            Exercise  `json:"exercise"`
+	FileDownloadBaseURL string    `json:"file_download_base_url"`
+	Files               []string  `json:"files"`
+	Iteration           Iteration `json:"iteration"`
+}
+
+// User is the person that a solution belongs to.
+type User struct {
+	Handle string `json:"handle"`
+	// IsRequester is true if the solution belongs to the person making the request.
+	IsRequester bool `json:"is_requester"`
+}
+
+// Exercise is the exercise that a solution solves.
+type Exercise struct {
+	ID              string `json:"id"`
+	InstructionsURL string `json:"instructions_url"`
+	AutoApprove     bool   `json:"auto_approve"`
+	Track           Track  `json:"track"`
+}
+
+// Iteration is the latest submission of a solution.
+type Iteration struct {
+	SubmittedAt *string `json:"submitted_at"`
+}
+
+// SolutionFile is a file that is submitted as part of a solution.
+type SolutionFile struct {
+	// Name is the path of the file within the exercise.
+	Name    string
+	Content io.Reader
+}
+
+// SolutionURL is the API endpoint of the solution with the given ID.
+func (c *Client) SolutionURL(id string) string {
+	return fmt.Sprintf("%s/solutions/%s", c.APIBaseURL, id)
+}
+
+// GetSolution fetches the solution with the given ID.
+func (c *Client) GetSolution(id string) (*Solution, error) {
+	return c.getSolution(c.SolutionURL(id))
+}
+
+// GetLatestSolution fetches the person's latest solution to an exercise.
+// If the track is empty the API works out which track is meant,
+// and reports a track_ambiguous error if it can't.
+func (c *Client) GetLatestSolution(track, exercise string) (*Solution, error) {
+	q := url.Values{}
+	q.Add("exercise_id", exercise)
+	if track != "" {
+		q.Add("track_id", track)
+	}
+	return c.getSolution(fmt.Sprintf("%s?%s", c.SolutionURL("latest"), q.Encode()))
+}
+
+func (c *Client) getSolution(url string) (*Solution, error) {
+	req, err := c.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Solution Solution `json:"solution"`
+	}
+	if err := c.decode(req, &payload); err != nil {
+		return nil, err
+	}
+	return &payload.Solution, nil
+}
+
+// UpdateSolution submits files as a new iteration of the solution.
+func (c *Client) UpdateSolution(id string, files []SolutionFile) error {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, file := range files {
+		part, err := writer.CreateFormFile("files[]", file.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	req, err := c.NewRequest("PATCH", c.SolutionURL(id), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return c.decode(req, nil)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSolution(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/solutions/bogus-id", r.URL.Path)
+		fmt.Fprint(w, `{
+			"solution": {
+				"id": "bogus-id",
+				"url": "http://example.com/bogus-url",
+				"user": {"handle": "alice", "is_requester": true},
+				"exercise": {
+					"id": "bogus-exercise",
+					"auto_approve": true,
+					"track": {"id": "bogus-track", "language": "Bogus"}
+				},
+				"file_download_base_url": "http://example.com/files/",
+				"files": ["file-1.txt"]
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("abc123", ts.URL)
+	assert.NoError(t, err)
+
+	solution, err := client.GetSolution("bogus-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "bogus-id", solution.ID)
+	assert.Equal(t, "alice", solution.User.Handle)
+	assert.True(t, solution.User.IsRequester)
+	assert.Equal(t, "bogus-exercise", solution.Exercise.ID)
+	assert.True(t, solution.Exercise.AutoApprove)
+	assert.Equal(t, "bogus-track", solution.Exercise.Track.ID)
+	assert.Equal(t, []string{"file-1.txt"}, solution.Files)
+}
+
+func TestGetLatestSolution(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		track string
+		query string
+	}{
+		{
+			desc:  "with a track",
+			track: "bogus-track",
+			query: "exercise_id=bogus-exercise&track_id=bogus-track",
+		},
+		{
+			desc:  "without a track",
+			track: "",
+			query: "exercise_id=bogus-exercise",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/solutions/latest", r.URL.Path)
+				assert.Equal(t, tc.query, r.URL.RawQuery)
+				fmt.Fprint(w, `{"solution": {"id": "bogus-id"}}`)
+			}))
+			defer ts.Close()
+
+			client, err := NewClient("abc123", ts.URL)
+			assert.NoError(t, err)
+
+			solution, err := client.GetLatestSolution(tc.track, "bogus-exercise")
+			assert.NoError(t, err)
+			assert.Equal(t, "bogus-id", solution.ID)
+		})
+	}
+}
+
+func TestGetSolutionErrors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		status  int
+		body    string
+		errType string
+		message string
+		tracks  []string
+	}{
+		{
+			desc:    "ambiguous track",
+			status:  http.StatusBadRequest,
+			body:    `{"error": {"type": "track_ambiguous", "message": "Please specify a track", "possible_track_ids": ["go", "ruby"]}}`,
+			errType: "track_ambiguous",
+			message: "Please specify a track: go, ruby",
+			tracks:  []string{"go", "ruby"},
+		},
+		{
+			desc:    "not found",
+			status:  http.StatusNotFound,
+			body:    `{"error": {"type": "solution_not_found", "message": "Solution not found"}}`,
+			errType: "solution_not_found",
+			message: "Solution not found",
+		},
+		{
+			desc:    "no error in the body",
+			status:  http.StatusInternalServerError,
+			body:    `oops`,
+			message: "500 Internal Server Error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer ts.Close()
+
+			client := &Client{APIBaseURL: ts.URL}
+			_, err := client.GetSolution("bogus-id")
+
+			apiErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected an *Error, got %#v", err)
+			}
+			assert.Equal(t, tc.status, apiErr.StatusCode)
+			assert.Equal(t, tc.errType, apiErr.Type)
+			assert.Equal(t, tc.tracks, apiErr.PossibleTrackIDs)
+			assert.Equal(t, tc.message, apiErr.Error())
+		})
+	}
+}
+
+func TestUpdateSolution(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/solutions/bogus-id", r.URL.Path)
+
+		err := r.ParseMultipartForm(2 << 10)
+		assert.NoError(t, err)
+
+		headers := r.MultipartForm.File["files[]"]
+		if !assert.Equal(t, 2, len(headers)) {
+			return
+		}
+		for i, expected := range []string{"one", "two"} {
+			file, err := headers[i].Open()
+			assert.NoError(t, err)
+			b, err := ioutil.ReadAll(file)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(b))
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("abc123", ts.URL)
+	assert.NoError(t, err)
+
+	files := []SolutionFile{
+		{Name: "/one.txt", Content: strings.NewReader("one")},
+		{Name: "/two.txt", Content: strings.NewReader("two")},
+	}
+	err = client.UpdateSolution("bogus-id", files)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,26 @@
+package api
+
+import "fmt"
+
+// Track is a language track on Exercism.
+type Track struct {
+	ID       string `json:"id"`
+	Language string `json:"language"`
+	// TestPattern matches the names of the test files in the track's exercises.
+	TestPattern string `json:"test_pattern"`
+}
+
+// GetTrack fetches the track with the given ID.
+func (c *Client) GetTrack(id string) (*Track, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s/tracks/%s", c.APIBaseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Track Track `json:"track"`
+	}
+	if err := c.decode(req, &payload); err != nil {
+		return nil, err
+	}
+	return &payload.Track, nil
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTrack(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tracks/bogus", r.URL.Path)
+		fmt.Fprint(w, `{"track": {"id": "bogus", "language": "Bogus", "test_pattern": "_spec[.]ext$"}}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("abc123", ts.URL)
+	assert.NoError(t, err)
+
+	track, err := client.GetTrack("bogus")
+	assert.NoError(t, err)
+	assert.Equal(t, "bogus", track.ID)
+	assert.Equal(t, "Bogus", track.Language)
+	assert.Equal(t, "_spec[.]ext$", track.TestPattern)
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -14,7 +13,7 @@ type Asset struct {
 	ContentType string `json:"content_type"`
 }
 
-func (a *Asset) download() (*bytes.Reader, error) {
+func (a *Asset) download() ([]byte, error) {
 	downloadURL := fmt.Sprintf("%s/assets/%d", ReleaseURL, a.ID)
 	req, err := http.NewRequest("GET", downloadURL, nil)
 	if err != nil {
@@ -22,16 +21,15 @@ func (a *Asset) download() (*bytes.Reader, error) {
 	}
 	// https://developer.github.com/v3/repos/releases/#get-a-single-release-asset
 	req.Header.Set("Accept", "application/octet-stream")
-	res, err := http.DefaultClient.Do(req)
+	res, err := HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	bs, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %s", res.Status)
 	}
 
-	return bytes.NewReader(bs), nil
+	return ioutil.ReadAll(res.Body)
 }
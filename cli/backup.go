@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	update "github.com/inconshreveable/go-update"
+)
+
+const historyFilename = "history.json"
+
+// executablePath is the binary that gets backed up and replaced.
+// If it is empty, it is the running executable.
+// It is overridden in tests.
+var executablePath = ""
+
+// Backups keeps copies of previous versions of the CLI,
+// along with a history of upgrades, so that an upgrade can be undone.
+type Backups struct {
+	Dir string
+}
+
+// NewBackups stores backups in the given directory.
+func NewBackups(dir string) *Backups {
+	return &Backups{Dir: dir}
+}
+
+// UpgradeRecord describes a change from one version of the CLI to another.
+type UpgradeRecord struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+	// Backup is the copy of the binary that was replaced.
+	Backup string `json:"backup,omitempty"`
+	// Rollback is true if this undid a previous upgrade.
+	Rollback bool `json:"rollback"`
+}
+
+// Save copies the current binary into the backup directory.
+// The binary is copied rather than moved aside by go-update,
+// since the config directory may be on a different device.
+func (b *Backups) Save(version string) (string, error) {
+	src, err := executable()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(b.Dir, os.FileMode(0755)); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("exercism-%s", version)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(b.Dir, name)
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(0755))
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// History lists the recorded upgrades, oldest first.
+func (b *Backups) History() ([]UpgradeRecord, error) {
+	bs, err := ioutil.ReadFile(filepath.Join(b.Dir, historyFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var history []UpgradeRecord
+	if err := json.Unmarshal(bs, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Record adds an upgrade to the history.
+func (b *Backups) Record(record UpgradeRecord) error {
+	history, err := b.History()
+	if err != nil {
+		return err
+	}
+	history = append(history, record)
+
+	bs, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(b.Dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(b.Dir, historyFilename), bs, os.FileMode(0644))
+}
+
+// Rollback restores the binary that was replaced by the most recent upgrade.
+func (c *CLI) Rollback() error {
+	if c.Backups == nil {
+		return errors.New("no backups are kept, unable to roll back")
+	}
+	history, err := c.Backups.History()
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return errors.New("there is no upgrade to roll back")
+	}
+
+	last := history[len(history)-1]
+	if last.Rollback {
+		return fmt.Errorf("the upgrade to %s has already been rolled back", last.From)
+	}
+	if last.Backup == "" {
+		return fmt.Errorf("no backup of %s was kept, unable to roll back", last.From)
+	}
+
+	bin, err := os.Open(last.Backup)
+	if err != nil {
+		return fmt.Errorf("unable to open the backup of %s: %s", last.From, err)
+	}
+	defer bin.Close()
+
+	if err := update.Apply(bin, update.Options{TargetPath: executablePath}); err != nil {
+		return err
+	}
+
+	return c.Backups.Record(UpgradeRecord{
+		From:     c.Version,
+		To:       last.From,
+		At:       time.Now(),
+		Rollback: true,
+	})
+}
+
+func executable() (string, error) {
+	if executablePath != "" {
+		return executablePath, nil
+	}
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
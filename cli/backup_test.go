@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rollback")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bin := filepath.Join(dir, "exercism")
+	err = ioutil.WriteFile(bin, []byte("version 1"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	oldExecutablePath := executablePath
+	executablePath = bin
+	defer func() { executablePath = oldExecutablePath }()
+
+	backups := NewBackups(filepath.Join(dir, "upgrades"))
+
+	// Nothing to roll back yet.
+	c := &CLI{Version: "1.0.0", Backups: backups}
+	assert.Error(t, c.Rollback())
+
+	// Pretend to upgrade from version 1 to version 2.
+	backup, err := backups.Save("1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "upgrades", "exercism-1.0.0"), backup)
+	err = ioutil.WriteFile(bin, []byte("version 2"), os.FileMode(0755))
+	assert.NoError(t, err)
+	err = backups.Record(UpgradeRecord{From: "1.0.0", To: "2.0.0", Backup: backup})
+	assert.NoError(t, err)
+
+	c = &CLI{Version: "2.0.0", Backups: backups}
+	err = c.Rollback()
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadFile(bin)
+	assert.NoError(t, err)
+	assert.Equal(t, "version 1", string(b))
+
+	history, err := backups.History()
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(history)) {
+		assert.Equal(t, "2.0.0", history[1].From)
+		assert.Equal(t, "1.0.0", history[1].To)
+		assert.True(t, history[1].Rollback)
+	}
+
+	// It can't be rolled back twice.
+	c = &CLI{Version: "1.0.0", Backups: backups}
+	assert.Error(t, c.Rollback())
+}
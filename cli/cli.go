@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -42,11 +43,15 @@ var (
 	}
 )
 
+// DefaultReleaseURL is where the CLI releases are published.
+const DefaultReleaseURL = "https://api.github.com/repos/exercism/cli/releases"
+
 var (
 	// HTTPClient is the client used to make HTTP calls in the cli package.
 	HTTPClient = &http.Client{Timeout: 10 * time.Second}
 	// ReleaseURL is the endpoint that provides information about cli releases.
-	ReleaseURL = "https://api.github.com/repos/exercism/cli/releases"
+	// It can be pointed at a mirror that serves the same JSON.
+	ReleaseURL = DefaultReleaseURL
 )
 
 // Updater is a simple upgradable file interface.
@@ -55,10 +60,30 @@ type Updater interface {
 	Upgrade() error
 }
 
+const (
+	// ChannelStable only offers full releases.
+	ChannelStable = "stable"
+	// ChannelBeta also offers pre-releases.
+	ChannelBeta = "beta"
+)
+
 // CLI is information about the CLI itself.
 type CLI struct {
 	Version       string
 	LatestRelease *Release
+	// Channel is the release channel to upgrade from.
+	// It defaults to the stable channel.
+	Channel string
+	// Constraint is a semver range that releases must satisfy,
+	// e.g. ">=3.0.0 <4.0.0".
+	Constraint string
+	// TargetVersion pins the CLI to a specific version.
+	TargetVersion string
+	// Backups keeps the binaries replaced by upgrades, if set.
+	Backups *Backups
+	// Insecure lets UpgradeFromFile install archives
+	// that can't be checked.
+	Insecure bool
 }
 
 // New creates a CLI, setting it to a particular version.
@@ -85,9 +110,74 @@ func (c *CLI) IsUpToDate() (bool, error) {
 		return false, fmt.Errorf("unable to parse current version (%s): %s", c.Version, err)
 	}
 
+	// When pinned to a version, any other version needs to change,
+	// even if that means going back to an older one.
+	if c.TargetVersion != "" {
+		return cv.Equals(rv), nil
+	}
+
+	inRange, err := c.allows(rv)
+	if err != nil {
+		return false, err
+	}
+	if !inRange {
+		return true, nil
+	}
+
 	return cv.GTE(rv), nil
 }
 
+// Releases lists the recent releases on the CLI's channel that satisfy
+// its version constraint, newest first.
+func (c *CLI) Releases() ([]Release, error) {
+	var all []Release
+	if err := getJSON(ReleaseURL, &all, "failed to get the list of releases"); err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	versions := map[string]semver.Version{}
+	for _, rel := range all {
+		if rel.Draft {
+			continue
+		}
+		v, err := semver.Make(rel.Version())
+		if err != nil {
+			debug.Printf("Skipping release %s: %s\n", rel.TagName, err)
+			continue
+		}
+		if (rel.Prerelease || len(v.Pre) > 0) && c.Channel != ChannelBeta {
+			continue
+		}
+		ok, err := c.allows(v)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		versions[rel.TagName] = v
+		releases = append(releases, rel)
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return versions[releases[i].TagName].GT(versions[releases[j].TagName])
+	})
+	return releases, nil
+}
+
+// allows checks the version against the CLI's version constraint.
+func (c *CLI) allows(v semver.Version) (bool, error) {
+	if c.Constraint == "" {
+		return true, nil
+	}
+	inRange, err := semver.ParseRange(c.Constraint)
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint (%s): %s", c.Constraint, err)
+	}
+	return inRange(v), nil
+}
+
 // Upgrade allows the user to upgrade to the latest version of the CLI.
 func (c *CLI) Upgrade() error {
 	var (
@@ -107,53 +197,147 @@ func (c *CLI) Upgrade() error {
 		buildName = fmt.Sprintf("%s-v%s", buildName, BuildARM)
 	}
 
-	var downloadRC *bytes.Reader
-	for _, a := range c.LatestRelease.Assets {
-		if strings.Contains(a.Name, buildName) {
-			debug.Printf("Downloading %s\n", a.Name)
-			var err error
-			downloadRC, err = a.download()
-			if err != nil {
-				return fmt.Errorf("error downloading executable: %s", err)
-			}
-			break
-		}
-	}
-	if downloadRC == nil {
+	archive := c.LatestRelease.asset(func(a *Asset) bool {
+		return strings.Contains(a.Name, buildName) && !strings.HasSuffix(a.Name, signatureSuffix)
+	})
+	if archive == nil {
 		return fmt.Errorf("no executable found for %s/%s%s", BuildOS, BuildARCH, BuildARM)
 	}
 
-	bin, err := extractBinary(downloadRC, OS)
+	debug.Printf("Downloading %s\n", archive.Name)
+	b, err := archive.download()
+	if err != nil {
+		return wrapError(err, "error downloading executable")
+	}
+	if err := c.LatestRelease.verify(archive, b); err != nil {
+		return err
+	}
+
+	bin, err := extractBinary(bytes.NewReader(b), OS)
 	if err != nil {
 		return err
 	}
 	defer bin.Close()
 
-	return update.Apply(bin, update.Options{})
+	return c.apply(bin, c.LatestRelease.Version())
+}
+
+// UpgradeFromFile installs the CLI from a release archive on disk.
+// Zip archives are assumed to contain a Windows build, anything else
+// is treated as a gzipped tarball. The archive is checked against the
+// checksums file published with the release, and against its signature
+// if we have a public key, both of which must be in the same directory.
+// The version of the archive isn't known, so it isn't recorded.
+func (c *CLI) UpgradeFromFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if c.Insecure {
+		debug.Printf("Not checking %s\n", path)
+	} else if err := verifyFile(path, b); err != nil {
+		return err
+	}
+
+	OS := ""
+	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+		OS = "windows"
+	}
+	bin, err := extractBinary(bytes.NewReader(b), OS)
+	if err != nil {
+		return err
+	}
+	if bin == nil {
+		return fmt.Errorf("no executable found in %s", path)
+	}
+	defer bin.Close()
+
+	return c.apply(bin, "")
+}
+
+// apply replaces the current binary, keeping a backup if possible.
+func (c *CLI) apply(bin io.Reader, to string) error {
+	if c.Backups == nil {
+		return update.Apply(bin, update.Options{TargetPath: executablePath})
+	}
+
+	backup, err := c.Backups.Save(c.Version)
+	if err != nil {
+		return fmt.Errorf("unable to back up the current version: %s", err)
+	}
+	if err := update.Apply(bin, update.Options{TargetPath: executablePath}); err != nil {
+		return err
+	}
+	return c.Backups.Record(UpgradeRecord{
+		From:   c.Version,
+		To:     to,
+		At:     time.Now(),
+		Backup: backup,
+	})
 }
 
 func (c *CLI) fetchLatestRelease() error {
-	latestReleaseURL := fmt.Sprintf("%s/%s", ReleaseURL, "latest")
-	resp, err := HTTPClient.Get(latestReleaseURL)
+	if c.TargetVersion != "" {
+		tag := fmt.Sprintf("v%s", strings.TrimPrefix(c.TargetVersion, "v"))
+		var rel Release
+		if err := getJSON(fmt.Sprintf("%s/tags/%s", ReleaseURL, tag), &rel, fmt.Sprintf("failed to get release %s", tag)); err != nil {
+			return err
+		}
+		c.LatestRelease = &rel
+		return nil
+	}
+
+	// The latest release is always a stable one,
+	// so we only need to look further on the beta channel
+	// or if the latest release may be out of range.
+	if c.Channel != ChannelBeta && c.Constraint == "" {
+		latestReleaseURL := fmt.Sprintf("%s/%s", ReleaseURL, "latest")
+		var rel Release
+		if err := getJSON(latestReleaseURL, &rel, "failed to get the latest release"); err != nil {
+			return err
+		}
+		c.LatestRelease = &rel
+		return nil
+	}
+
+	releases, err := c.Releases()
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		if c.Constraint == "" {
+			return fmt.Errorf("no %s release found", c.channel())
+		}
+		return fmt.Errorf("no %s release found matching %s", c.channel(), c.Constraint)
+	}
+	c.LatestRelease = &releases[0]
+	return nil
+}
+
+func (c *CLI) channel() string {
+	if c.Channel == "" {
+		return ChannelStable
+	}
+	return c.Channel
+}
+
+func getJSON(url string, v interface{}, failure string) error {
+	resp, err := HTTPClient.Get(url)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode > 399 {
-		msg := "failed to get the latest release\n"
+		msg := failure + "\n"
 		for k, v := range resp.Header {
 			msg += fmt.Sprintf("\n  %s:\n    %s", k, v)
 		}
 		return fmt.Errorf(msg)
 	}
 
-	var rel Release
-	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
-		return err
-	}
-	c.LatestRelease = &rel
-	return nil
+	return json.NewDecoder(resp.Body).Decode(v)
 }
 
 func extractBinary(source *bytes.Reader, os string) (binary io.ReadCloser, err error) {
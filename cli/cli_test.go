@@ -1,9 +1,16 @@
 package cli
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -78,3 +85,217 @@ func TestIsUpToDateWithoutRelease(t *testing.T) {
 	assert.False(t, ok)
 	assert.NotNil(t, c.LatestRelease)
 }
+
+func TestIsUpToDateWithPinnedVersion(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		cliVersion string
+		releaseTag string
+		ok         bool
+	}{
+		{
+			desc:       "It returns true for the pinned version.",
+			cliVersion: "2.0.1",
+			releaseTag: "v2.0.1",
+			ok:         true,
+		},
+		{
+			desc:       "It returns false for versions greater than the pinned version.",
+			cliVersion: "2.0.2",
+			releaseTag: "v2.0.1",
+			ok:         false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := &CLI{
+				Version:       tc.cliVersion,
+				LatestRelease: &Release{TagName: tc.releaseTag},
+				TargetVersion: "2.0.1",
+			}
+
+			ok, err := c.IsUpToDate()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestReleases(t *testing.T) {
+	fakeEndpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprintln(w, `[
+				{"tag_name": "v3.0.0-alpha.4", "prerelease": true},
+				{"tag_name": "v2.4.1"},
+				{"tag_name": "v3.1.0", "draft": true},
+				{"tag_name": "v2.5.0"},
+				{"tag_name": "nonsense"}
+			]`)
+		case "/latest":
+			fmt.Fprintln(w, `{"tag_name": "v2.5.0"}`)
+		case "/tags/v2.4.1":
+			fmt.Fprintln(w, `{"tag_name": "v2.4.1"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ts := httptest.NewServer(fakeEndpoint)
+	defer ts.Close()
+
+	oldReleaseURL := ReleaseURL
+	ReleaseURL = ts.URL
+	defer func() { ReleaseURL = oldReleaseURL }()
+
+	testCases := []struct {
+		desc       string
+		channel    string
+		constraint string
+		target     string
+		expected   []string
+		latest     string
+	}{
+		{
+			desc:     "stable channel",
+			channel:  ChannelStable,
+			expected: []string{"v2.5.0", "v2.4.1"},
+			latest:   "v2.5.0",
+		},
+		{
+			desc:     "beta channel",
+			channel:  ChannelBeta,
+			expected: []string{"v3.0.0-alpha.4", "v2.5.0", "v2.4.1"},
+			latest:   "v3.0.0-alpha.4",
+		},
+		{
+			desc:       "with a constraint",
+			channel:    ChannelBeta,
+			constraint: "<2.5.0",
+			expected:   []string{"v2.4.1"},
+			latest:     "v2.4.1",
+		},
+		{
+			desc:     "pinned to a version",
+			channel:  ChannelBeta,
+			target:   "2.4.1",
+			expected: []string{"v3.0.0-alpha.4", "v2.5.0", "v2.4.1"},
+			latest:   "v2.4.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := &CLI{
+				Version:       "2.0.0",
+				Channel:       tc.channel,
+				Constraint:    tc.constraint,
+				TargetVersion: tc.target,
+			}
+
+			releases, err := c.Releases()
+			assert.NoError(t, err)
+			var tags []string
+			for _, rel := range releases {
+				tags = append(tags, rel.TagName)
+			}
+			assert.Equal(t, tc.expected, tags)
+
+			ok, err := c.IsUpToDate()
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			assert.Equal(t, tc.latest, c.LatestRelease.TagName)
+		})
+	}
+}
+
+func TestUpgradeFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upgrade-from-file")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bin := filepath.Join(dir, "exercism")
+	err = ioutil.WriteFile(bin, []byte("old version"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	oldExecutablePath := executablePath
+	executablePath = bin
+	defer func() { executablePath = oldExecutablePath }()
+
+	// Build a release archive.
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	contents := []byte("new version")
+	err = tw.WriteHeader(&tar.Header{Name: "exercism", Mode: 0755, Size: int64(len(contents))})
+	assert.NoError(t, err)
+	_, err = tw.Write(contents)
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+
+	downloads := filepath.Join(dir, "downloads")
+	err = os.Mkdir(downloads, os.FileMode(0755))
+	assert.NoError(t, err)
+	archive := filepath.Join(downloads, "exercism-linux-64bit.tgz")
+	err = ioutil.WriteFile(archive, buf.Bytes(), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	// Without a checksums file, nothing is installed.
+	c := &CLI{Version: "1.0.0", Backups: NewBackups(filepath.Join(dir, "upgrades"))}
+	err = c.UpgradeFromFile(archive)
+	assert.Error(t, err)
+
+	b, err := ioutil.ReadFile(bin)
+	assert.NoError(t, err)
+	assert.Equal(t, "old version", string(b))
+
+	// A checksums file that doesn't match stops the upgrade.
+	sums := filepath.Join(downloads, "exercism_checksums.txt")
+	err = ioutil.WriteFile(sums, []byte(fmt.Sprintf("%x  exercism-linux-64bit.tgz\n", sha256.Sum256([]byte("nope")))), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	err = c.UpgradeFromFile(archive)
+	assert.Error(t, err)
+
+	b, err = ioutil.ReadFile(bin)
+	assert.NoError(t, err)
+	assert.Equal(t, "old version", string(b))
+
+	// With matching checksums, it is installed.
+	err = ioutil.WriteFile(sums, []byte(fmt.Sprintf("%x  exercism-linux-64bit.tgz\n", sha256.Sum256(buf.Bytes()))), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	err = c.UpgradeFromFile(archive)
+	assert.NoError(t, err)
+
+	b, err = ioutil.ReadFile(bin)
+	assert.NoError(t, err)
+	assert.Equal(t, "new version", string(b))
+
+	// The old version was kept.
+	history, err := c.Backups.History()
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(history)) {
+		b, err = ioutil.ReadFile(history[0].Backup)
+		assert.NoError(t, err)
+		assert.Equal(t, "old version", string(b))
+		assert.Equal(t, "", history[0].To)
+	}
+
+	// Unless it is insecure, an archive that can't be checked isn't installed.
+	err = ioutil.WriteFile(bin, []byte("old version"), os.FileMode(0755))
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(sums))
+
+	err = c.UpgradeFromFile(archive)
+	assert.Error(t, err)
+
+	c.Insecure = true
+	err = c.UpgradeFromFile(archive)
+	assert.NoError(t, err)
+
+	b, err = ioutil.ReadFile(bin)
+	assert.NoError(t, err)
+	assert.Equal(t, "new version", string(b))
+}
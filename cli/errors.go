@@ -0,0 +1,23 @@
+package cli
+
+import "fmt"
+
+// wrappedError adds context to the message of an error,
+// and keeps the error itself around, so that callers can tell what went wrong.
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e *wrappedError) Error() string {
+	return fmt.Sprintf("%s: %s", e.msg, e.err)
+}
+
+// Cause is the error that was wrapped.
+func (e *wrappedError) Cause() error {
+	return e.err
+}
+
+func wrapError(err error, format string, a ...interface{}) error {
+	return &wrappedError{msg: fmt.Sprintf(format, a...), err: err}
+}
@@ -1,12 +1,19 @@
 package cli
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // Release is a specific build of the CLI, released on GitHub.
 type Release struct {
-	Location string  `json:"html_url"`
-	TagName  string  `json:"tag_name"`
-	Assets   []Asset `json:"assets"`
+	Location    string    `json:"html_url"`
+	TagName     string    `json:"tag_name"`
+	Assets      []Asset   `json:"assets"`
+	Body        string    `json:"body"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
 }
 
 // Version is the CLI version that is built for the release.
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/exercism/cli/debug"
+	update "github.com/inconshreveable/go-update"
+)
+
+// PublicKey is the PEM encoded ECDSA public key that release signatures are
+// checked against. If it is set, every archive must come with a valid
+// detached signature, uploaded as an asset named after the archive with a
+// .sig suffix. Release builds set it with -ldflags, and bin/build-all
+// refuses to build without it.
+var PublicKey = ""
+
+const (
+	// checksumsSuffix identifies the release asset that lists
+	// the SHA-256 checksums of the archives, in sha256sum format.
+	checksumsSuffix = "checksums.txt"
+	// signatureSuffix is appended to the name of an archive
+	// to get the name of its signature.
+	signatureSuffix = ".sig"
+)
+
+// verify checks a downloaded archive against the checksums published with the
+// release, and against its signature if we have a public key to check it with.
+func (r *Release) verify(archive *Asset, b []byte) error {
+	checksums := r.asset(func(a *Asset) bool { return strings.HasSuffix(a.Name, checksumsSuffix) })
+	if checksums == nil {
+		return fmt.Errorf("release %s has no checksums, refusing to upgrade", r.TagName)
+	}
+	debug.Printf("Downloading %s\n", checksums.Name)
+	sums, err := checksums.download()
+	if err != nil {
+		return wrapError(err, "error downloading checksums")
+	}
+	if err := verifyChecksum(sums, archive.Name, b); err != nil {
+		return err
+	}
+
+	if PublicKey == "" {
+		debug.Println("No public key to check signatures with")
+		return nil
+	}
+	signature := r.asset(func(a *Asset) bool { return a.Name == archive.Name+signatureSuffix })
+	if signature == nil {
+		return fmt.Errorf("release %s has no signature for %s, refusing to upgrade", r.TagName, archive.Name)
+	}
+	debug.Printf("Downloading %s\n", signature.Name)
+	sig, err := signature.download()
+	if err != nil {
+		return wrapError(err, "error downloading signature")
+	}
+	return verifySignature(sig, b, PublicKey)
+}
+
+// verifyFile checks an archive on disk against the checksums file and the
+// signature next to it, the same way verify checks a downloaded release.
+func verifyFile(path string, b []byte) error {
+	name := filepath.Base(path)
+	sums, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*"+checksumsSuffix))
+	if err != nil {
+		return err
+	}
+	if len(sums) == 0 {
+		return fmt.Errorf("no checksums found next to %s, refusing to upgrade", name)
+	}
+	checksums, err := ioutil.ReadFile(sums[0])
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksum(checksums, name, b); err != nil {
+		return err
+	}
+
+	if PublicKey == "" {
+		debug.Println("No public key to check signatures with")
+		return nil
+	}
+	sig, err := ioutil.ReadFile(path + signatureSuffix)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no signature found for %s, refusing to upgrade", name)
+	}
+	if err != nil {
+		return err
+	}
+	return verifySignature(sig, b, PublicKey)
+}
+
+// asset finds the first asset of the release that matches.
+func (r *Release) asset(fn func(*Asset) bool) *Asset {
+	for i := range r.Assets {
+		if fn(&r.Assets[i]) {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// verifyChecksum checks the SHA-256 of the named file
+// against a list of checksums in sha256sum format.
+func verifyChecksum(checksums []byte, name string, b []byte) error {
+	sum := sha256.Sum256(b)
+	for _, line := range strings.Split(string(checksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		expected, err := hex.DecodeString(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid checksum for %s: %s", name, err)
+		}
+		if !bytes.Equal(expected, sum[:]) {
+			return fmt.Errorf("checksum mismatch for %s, refusing to upgrade: expected %x, got %x", name, expected, sum)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum found for %s, refusing to upgrade", name)
+}
+
+// verifySignature checks an ECDSA signature of the SHA-256 of the data.
+// The signature may be DER encoded, or DER encoded and then base64 encoded.
+func verifySignature(signature, b []byte, publicKeyPEM string) error {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return errors.New("unable to parse the public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse the public key: %s", err)
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+
+	sum := sha256.Sum256(b)
+	if err := update.NewECDSAVerifier().VerifySignature(sum[:], signature, crypto.SHA256, key); err != nil {
+		return fmt.Errorf("invalid signature, refusing to upgrade: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseVerify(t *testing.T) {
+	archive := []byte("this is the archive")
+	sum := sha256.Sum256(archive)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+	assert.NoError(t, err)
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	assert.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	r, s, err = ecdsa.Sign(rand.Reader, otherKey, sum[:])
+	assert.NoError(t, err)
+	badSignature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	assert.NoError(t, err)
+
+	goodSums := fmt.Sprintf("%x  exercism-linux-64bit.tgz\n%x  exercism-mac-64bit.tgz\n", sum, sha256.Sum256([]byte("other")))
+	badSums := fmt.Sprintf("%x  exercism-linux-64bit.tgz\n", sha256.Sum256([]byte("tampered")))
+
+	testCases := []struct {
+		desc      string
+		assets    map[string][]byte
+		publicKey string
+		message   string
+	}{
+		{
+			desc:   "checksum matches",
+			assets: map[string][]byte{"checksums.txt": []byte(goodSums)},
+		},
+		{
+			desc:    "checksum doesn't match",
+			assets:  map[string][]byte{"checksums.txt": []byte(badSums)},
+			message: "checksum mismatch",
+		},
+		{
+			desc:    "no checksums",
+			assets:  map[string][]byte{},
+			message: "no checksums",
+		},
+		{
+			desc:    "archive missing from checksums",
+			assets:  map[string][]byte{"checksums.txt": []byte("abcd  exercism-windows-64bit.zip\n")},
+			message: "no checksum found",
+		},
+		{
+			desc: "valid signature",
+			assets: map[string][]byte{
+				"checksums.txt":                []byte(goodSums),
+				"exercism-linux-64bit.tgz.sig": signature,
+			},
+			publicKey: publicKey,
+		},
+		{
+			desc: "invalid signature",
+			assets: map[string][]byte{
+				"checksums.txt":                []byte(goodSums),
+				"exercism-linux-64bit.tgz.sig": badSignature,
+			},
+			publicKey: publicKey,
+			message:   "invalid signature",
+		},
+		{
+			desc:      "missing signature",
+			assets:    map[string][]byte{"checksums.txt": []byte(goodSums)},
+			publicKey: publicKey,
+			message:   "no signature",
+		},
+	}
+
+	oldReleaseURL := ReleaseURL
+	oldPublicKey := PublicKey
+	defer func() {
+		ReleaseURL = oldReleaseURL
+		PublicKey = oldPublicKey
+	}()
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			release := &Release{
+				TagName: "v3.0.0",
+				Assets:  []Asset{{ID: 1, Name: "exercism-linux-64bit.tgz"}},
+			}
+			contents := map[string][]byte{}
+			id := 2
+			for name, b := range tc.assets {
+				path := fmt.Sprintf("/assets/%d", id)
+				release.Assets = append(release.Assets, Asset{ID: id, Name: name})
+				contents[path] = b
+				id++
+			}
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, ok := contents[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write(b)
+			}))
+			defer ts.Close()
+			ReleaseURL = ts.URL
+			PublicKey = tc.publicKey
+
+			err := release.verify(&release.Assets[0], archive)
+			if tc.message == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.message)
+			}
+
+			// The same assets next to an archive on disk.
+			dir, err := ioutil.TempDir("", "verify-file")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			for name, b := range tc.assets {
+				err = ioutil.WriteFile(filepath.Join(dir, name), b, os.FileMode(0644))
+				assert.NoError(t, err)
+			}
+			path := filepath.Join(dir, "exercism-linux-64bit.tgz")
+			err = ioutil.WriteFile(path, archive, os.FileMode(0644))
+			assert.NoError(t, err)
+
+			err = verifyFile(path, archive)
+			if tc.message == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.message)
+			}
+		})
+	}
+}
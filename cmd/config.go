@@ -0,0 +1,480 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/exercism/cli/cli"
+	"github.com/exercism/cli/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configCmd reads and writes individual settings.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and write individual settings.",
+	Long: `Read and write individual settings.
+
+Settings are stored in two files in the config directory.
+The token, workspace, and API base URL are stored in user.json,
+which belongs to the active profile. Everything else is stored
+in cli.json.
+
+Track settings are named after the track, for example
+tracks.go.ignorepatterns.
+
+Call the list subcommand to see every setting, along with
+where its value comes from: a file, an environment variable,
+or the default.
+
+The token is censored by get and list. Pass --full-token
+to see all of it.
+	`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print the value of a setting.",
+	Args:  validArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfiguration()
+		if err != nil {
+			return err
+		}
+		return runConfigGet(cfg, cmd.Flags(), args)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE...",
+	Short: "Change the value of a setting.",
+	Long: `Change the value of a setting.
+
+Settings that hold a list, such as tracks.<track>.ignorepatterns,
+take any number of values.
+	`,
+	Args: validArgs(cobra.MinimumNArgs(2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfiguration()
+		if err != nil {
+			return err
+		}
+		return runConfigSet(cfg, cmd.Flags(), args)
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset KEY",
+	Short: "Go back to the default value of a setting.",
+	Args:  validArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfiguration()
+		if err != nil {
+			return err
+		}
+		return runConfigUnset(cfg, cmd.Flags(), args)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List all the settings and where they come from.",
+	Args:    validArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfiguration()
+		if err != nil {
+			return err
+		}
+		return runConfigList(cfg, cmd.Flags(), args)
+	},
+}
+
+// loadConfiguration reads both the user config of the active profile and the CLI config.
+func loadConfiguration() (config.Configuration, error) {
+	cfg := config.NewConfiguration()
+
+	usrCfg, err := config.NewUserViperConfig(cfg.UserDir)
+	if err != nil {
+		return cfg, err
+	}
+	// Don't let a broken credential store get in the way of configuring a different one.
+	if err := config.LoadCredentials(usrCfg); err != nil {
+		fmt.Fprintf(Err, "Warning: unable to read the token from the credential store: %s\n", err)
+	}
+	cfg.UserViperConfig = usrCfg
+
+	cliCfg, err := config.NewCLIConfig()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.CLIConfig = cliCfg
+	return cfg, nil
+}
+
+// loadWithoutCredentials loads the configuration for commands that don't need the token,
+// so that a broken credential store doesn't get in their way.
+func loadWithoutCredentials() (config.Configuration, error) {
+	cfg, err := config.Load()
+	if _, ok := err.(*config.CredentialsError); ok {
+		return cfg, nil
+	}
+	return cfg, err
+}
+
+// Sources of a setting.
+const (
+	sourceFile        = "file"
+	sourceEnvironment = "environment"
+	sourceDefault     = "default"
+)
+
+// configValue is the current value of a setting, and where it comes from.
+type configValue struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+	Source string   `json:"source"`
+	// Origin is the file or environment variable that provides the value.
+	Origin string `json:"origin,omitempty"`
+}
+
+func (cv configValue) String() string {
+	return strings.Join(cv.Values, ", ")
+}
+
+// censor masks the token, unless the full token was asked for.
+func (cv configValue) censor(full bool) configValue {
+	if cv.Key != "token" {
+		return cv
+	}
+	values := make([]string, len(cv.Values))
+	for i, value := range cv.Values {
+		values[i] = censorToken(value, full)
+	}
+	cv.Values = values
+	return cv
+}
+
+// configKey describes a setting that the config command can manage.
+type configKey struct {
+	name string
+	// env is the environment variable that overrides the setting, if any.
+	env string
+	// list is true if the setting holds more than one value.
+	list bool
+	// get returns the value stored in the file, and whether there is one.
+	get func(config.Configuration) ([]string, bool)
+	// fallback is the value that is used if nothing is configured.
+	fallback func(config.Configuration) []string
+	set      func(*config.Configuration, []string) error
+	unset    func(*config.Configuration) error
+	// file is the config file that stores the setting.
+	file func(config.Configuration) string
+}
+
+func (k configKey) value(cfg config.Configuration) configValue {
+	if k.env != "" {
+		if value := os.Getenv(k.env); value != "" {
+			return configValue{Key: k.name, Values: []string{value}, Source: sourceEnvironment, Origin: k.env}
+		}
+	}
+	if values, ok := k.get(cfg); ok {
+		return configValue{Key: k.name, Values: values, Source: sourceFile, Origin: k.file(cfg)}
+	}
+	values := []string{}
+	if k.fallback != nil {
+		values = k.fallback(cfg)
+	}
+	return configValue{Key: k.name, Values: values, Source: sourceDefault}
+}
+
+func userFile(cfg config.Configuration) string {
+	return filepath.Join(cfg.UserDir, "user.json")
+}
+
+func cliFile(cfg config.Configuration) string {
+	return cfg.CLIConfig.File()
+}
+
+// userConfigKey is a setting stored in the user config of the active profile.
+func userConfigKey(name string, fallback func(config.Configuration) []string, normalize func(config.Configuration, string) string) configKey {
+	env, _ := config.EnvOverride(name)
+	return configKey{
+		name: name,
+		env:  env,
+		file: userFile,
+		get: func(cfg config.Configuration) ([]string, bool) {
+			v := cfg.UserViperConfig
+			if !v.IsSet(name) || v.GetString(name) == "" {
+				return nil, false
+			}
+			return []string{v.GetString(name)}, true
+		},
+		fallback: fallback,
+		set: func(cfg *config.Configuration, values []string) error {
+			value := values[0]
+			if normalize != nil {
+				value = normalize(*cfg, value)
+			}
+			config.SetExplicitly(cfg.UserViperConfig, name, value)
+			return cfg.Save("user")
+		},
+		unset: func(cfg *config.Configuration) error {
+			// Viper can't forget a key, so start over without it.
+			v := viper.New()
+			for key, value := range cfg.UserViperConfig.AllSettings() {
+				if key != name {
+					v.Set(key, value)
+				}
+			}
+			cfg.UserViperConfig = v
+			return cfg.Save("user")
+		},
+	}
+}
+
+// cliConfigKey is a setting stored in the CLI config.
+func cliConfigKey(name string, field func(*config.CLIConfig) *string, fallback string) configKey {
+	return configKey{
+		name: name,
+		file: cliFile,
+		get: func(cfg config.Configuration) ([]string, bool) {
+			value := *field(cfg.CLIConfig)
+			return []string{value}, value != ""
+		},
+		fallback: func(config.Configuration) []string {
+			return []string{fallback}
+		},
+		set: func(cfg *config.Configuration, values []string) error {
+			previous := *field(cfg.CLIConfig)
+			*field(cfg.CLIConfig) = values[0]
+			if err := cfg.CLIConfig.Write(); err != nil {
+				*field(cfg.CLIConfig) = previous
+				return err
+			}
+			return nil
+		},
+		unset: func(cfg *config.Configuration) error {
+			*field(cfg.CLIConfig) = ""
+			return cfg.CLIConfig.Write()
+		},
+	}
+}
+
+// configKeys are the settings that always exist.
+// Track settings are added for each configured track.
+var configKeys = []configKey{
+	userConfigKey("token", nil, nil),
+	userConfigKey("workspace",
+		func(cfg config.Configuration) []string {
+			return []string{config.DefaultWorkspaceDir(cfg)}
+		},
+		func(cfg config.Configuration, value string) string {
+			return config.Resolve(value, cfg.Home)
+		},
+	),
+	userConfigKey("apibaseurl",
+		func(cfg config.Configuration) []string {
+			return []string{cfg.DefaultBaseURL}
+		},
+		nil,
+	),
+	userConfigKey("credentialstore",
+		func(config.Configuration) []string {
+			return []string{"file"}
+		},
+		nil,
+	),
+	cliConfigKey("upgrade.channel", func(c *config.CLIConfig) *string { return &c.Upgrade.Channel }, cli.ChannelStable),
+	cliConfigKey("upgrade.constraint", func(c *config.CLIConfig) *string { return &c.Upgrade.Constraint }, ""),
+	cliConfigKey("upgrade.releaseurl", func(c *config.CLIConfig) *string { return &c.Upgrade.ReleaseURL }, cli.DefaultReleaseURL),
+	cliConfigKey("upgrade.checkinterval", func(c *config.CLIConfig) *string { return &c.Upgrade.CheckInterval }, "24h"),
+	{
+		name: "upgrade.disablecheck",
+		file: cliFile,
+		get: func(cfg config.Configuration) ([]string, bool) {
+			return []string{strconv.FormatBool(cfg.CLIConfig.Upgrade.DisableCheck)}, cfg.CLIConfig.Upgrade.DisableCheck
+		},
+		fallback: func(config.Configuration) []string {
+			return []string{"false"}
+		},
+		set: func(cfg *config.Configuration, values []string) error {
+			disable, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return newError(errorValidation, fmt.Errorf("upgrade.disablecheck must be true or false, not '%s'", values[0]))
+			}
+			cfg.CLIConfig.Upgrade.DisableCheck = disable
+			return cfg.CLIConfig.Write()
+		},
+		unset: func(cfg *config.Configuration) error {
+			cfg.CLIConfig.Upgrade.DisableCheck = false
+			return cfg.CLIConfig.Write()
+		},
+	},
+}
+
+// trackConfigKey is the list of ignore patterns for a track.
+func trackConfigKey(id string) configKey {
+	return configKey{
+		name: fmt.Sprintf("tracks.%s.ignorepatterns", id),
+		file: cliFile,
+		list: true,
+		get: func(cfg config.Configuration) ([]string, bool) {
+			track, ok := cfg.CLIConfig.Tracks[id]
+			if !ok {
+				return nil, false
+			}
+			return track.IgnorePatterns, true
+		},
+		fallback: func(config.Configuration) []string {
+			return config.NewTrack(id).IgnorePatterns
+		},
+		set: func(cfg *config.Configuration, values []string) error {
+			previous, ok := cfg.CLIConfig.Tracks[id]
+			cfg.CLIConfig.Tracks[id] = &config.Track{ID: id, IgnorePatterns: values}
+			if err := cfg.CLIConfig.Write(); err != nil {
+				if ok {
+					cfg.CLIConfig.Tracks[id] = previous
+				} else {
+					delete(cfg.CLIConfig.Tracks, id)
+				}
+				return err
+			}
+			return nil
+		},
+		unset: func(cfg *config.Configuration) error {
+			delete(cfg.CLIConfig.Tracks, id)
+			return cfg.CLIConfig.Write()
+		},
+	}
+}
+
+func findConfigKey(name string) (configKey, error) {
+	name = strings.ToLower(name)
+	for _, key := range configKeys {
+		if key.name == name {
+			return key, nil
+		}
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) == 3 && parts[0] == "tracks" && parts[1] != "" && parts[2] == "ignorepatterns" {
+		return trackConfigKey(parts[1]), nil
+	}
+	return configKey{}, newError(errorValidation, fmt.Errorf("unknown setting '%s', run '%s config list' to see the available settings", name, BinaryName))
+}
+
+func runConfigGet(cfg config.Configuration, flags *pflag.FlagSet, args []string) error {
+	key, err := findConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	fullToken, err := flags.GetBool("full-token")
+	if err != nil {
+		return err
+	}
+	value := key.value(cfg).censor(fullToken)
+	if wantsJSON(flags) {
+		return writeJSON(Out, value)
+	}
+	for _, v := range value.Values {
+		fmt.Fprintln(Out, v)
+	}
+	if value.Origin == "" {
+		fmt.Fprintf(Err, "(%s)\n", value.Source)
+	} else {
+		fmt.Fprintf(Err, "(%s: %s)\n", value.Source, value.Origin)
+	}
+	return nil
+}
+
+func runConfigSet(cfg config.Configuration, flags *pflag.FlagSet, args []string) error {
+	key, err := findConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	values := args[1:]
+	if !key.list && len(values) > 1 {
+		return newError(errorValidation, fmt.Errorf("%s takes a single value", key.name))
+	}
+	if err := key.set(&cfg, values); err != nil {
+		return err
+	}
+	if key.env != "" && os.Getenv(key.env) != "" {
+		fmt.Fprintf(Err, "Note: %s is set, and takes precedence over the saved value.\n", key.env)
+	}
+	return nil
+}
+
+func runConfigUnset(cfg config.Configuration, flags *pflag.FlagSet, args []string) error {
+	key, err := findConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	return key.unset(&cfg)
+}
+
+func runConfigList(cfg config.Configuration, flags *pflag.FlagSet, args []string) error {
+	keys := append([]configKey{}, configKeys...)
+	ids := make([]string, 0, len(cfg.CLIConfig.Tracks))
+	for id := range cfg.CLIConfig.Tracks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		keys = append(keys, trackConfigKey(id))
+	}
+
+	fullToken, err := flags.GetBool("full-token")
+	if err != nil {
+		return err
+	}
+	values := make([]configValue, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, key.value(cfg).censor(fullToken))
+	}
+
+	if wantsJSON(flags) {
+		return writeJSON(Out, values)
+	}
+	writeConfigValues(Out, values)
+	return nil
+}
+
+func writeConfigValues(w io.Writer, values []configValue) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	for _, value := range values {
+		source := value.Source
+		if value.Origin != "" {
+			source = fmt.Sprintf("%s (%s)", value.Source, value.Origin)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", value.Key, value, source)
+	}
+}
+
+func initConfigGetCmd() {
+	configGetCmd.Flags().BoolP("full-token", "", false, "display the full token, censored by default")
+}
+
+func initConfigListCmd() {
+	configListCmd.Flags().BoolP("full-token", "", false, "display the full token, censored by default")
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	initConfigGetCmd()
+	initConfigListCmd()
+}
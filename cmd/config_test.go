@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetSetUnset(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	dir, err := ioutil.TempDir("", "config-cmd")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	cfg := config.Configuration{
+		Home:            "/home/alice",
+		UserDir:         dir,
+		OS:              "linux",
+		DefaultDirName:  "exercism",
+		DefaultBaseURL:  "http://example.com/api/v1",
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+		CLIConfig: &config.CLIConfig{
+			Config: config.New(dir, "cli"),
+			Tracks: config.Tracks{},
+		},
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	flags.Bool("full-token", false, "")
+	flags.String("output", outputText, "")
+
+	get := func(key string) string {
+		var buf bytes.Buffer
+		Out = &buf
+		err := runConfigGet(cfg, flags, []string{key})
+		assert.NoError(t, err)
+		return buf.String()
+	}
+
+	// Values from the file, and defaults.
+	// The token is censored unless the full token is asked for.
+	assert.Equal(t, "******\n", get("token"))
+	flags.Set("full-token", "true")
+	assert.Equal(t, "abc123\n", get("token"))
+	flags.Set("full-token", "false")
+	assert.Equal(t, "http://example.com/api/v1\n", get("apibaseurl"))
+	assert.Equal(t, filepath.Join("/home/alice", "exercism")+"\n", get("workspace"))
+	assert.Equal(t, "stable\n", get("upgrade.channel"))
+	assert.Equal(t, ".*[.]md\n[.]solution[.]json\n", get("tracks.go.ignorepatterns"))
+
+	// With --output=json, the value comes with its source.
+	flags.Set("output", outputJSON)
+	var value configValue
+	assert.NoError(t, json.Unmarshal([]byte(get("upgrade.channel")), &value))
+	assert.Equal(t, configValue{Key: "upgrade.channel", Values: []string{"stable"}, Source: sourceDefault}, value)
+	flags.Set("output", outputText)
+
+	// User settings.
+	err = runConfigSet(cfg, flags, []string{"workspace", "~/code/exercism"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/alice/code/exercism\n", get("workspace"))
+
+	err = runConfigUnset(cfg, flags, []string{"token"})
+	assert.NoError(t, err)
+
+	// CLI settings are validated and written to cli.json.
+	err = runConfigSet(cfg, flags, []string{"upgrade.channel", "beta"})
+	assert.NoError(t, err)
+	err = runConfigSet(cfg, flags, []string{"upgrade.channel", "nightly"})
+	assert.Error(t, err)
+
+	err = runConfigSet(cfg, flags, []string{"tracks.go.ignorepatterns", "_test[.]go$", "vendor"})
+	assert.NoError(t, err)
+	err = runConfigSet(cfg, flags, []string{"tracks.ruby.ignorepatterns", "(?=re)"})
+	assert.Error(t, err)
+
+	cliCfg := &config.CLIConfig{Config: config.New(dir, "cli")}
+	err = cliCfg.Load(viper.New())
+	assert.NoError(t, err)
+	assert.Equal(t, "beta", cliCfg.Upgrade.Channel)
+	assert.Equal(t, []string{".*[.]md", "[.]solution[.]json", "_test[.]go$", "vendor"}, cliCfg.Tracks["go"].IgnorePatterns)
+
+	err = runConfigUnset(cfg, flags, []string{"tracks.go.ignorepatterns"})
+	assert.NoError(t, err)
+	assert.Equal(t, ".*[.]md\n[.]solution[.]json\n", get("tracks.go.ignorepatterns"))
+
+	// Mistakes.
+	err = runConfigSet(cfg, flags, []string{"upgrade.channel", "beta", "stable"})
+	assert.Error(t, err)
+	err = runConfigGet(cfg, flags, []string{"bogus"})
+	assert.Error(t, err)
+}
+
+func TestConfigList(t *testing.T) {
+	oldOut := Out
+	defer func() { Out = oldOut }()
+
+	oldToken := os.Getenv("EXERCISM_TOKEN")
+	os.Unsetenv("EXERCISM_TOKEN")
+	defer os.Setenv("EXERCISM_TOKEN", oldToken)
+
+	v := viper.New()
+	v.Set("token", "1a11111aaaa111aa1a11111a11111aa1")
+	cfg := config.Configuration{
+		Home:            "/home/alice",
+		UserDir:         "/home/alice/.config/exercism",
+		OS:              "linux",
+		DefaultDirName:  "exercism",
+		DefaultBaseURL:  "http://example.com/api/v1",
+		UserViperConfig: v,
+		CLIConfig: &config.CLIConfig{
+			Config: config.New("/home/alice/.config/exercism", "cli"),
+			Tracks: config.Tracks{
+				"go": config.NewTrack("go"),
+			},
+		},
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	flags.String("output", outputJSON, "")
+	flags.Bool("full-token", false, "")
+
+	var buf bytes.Buffer
+	Out = &buf
+	err := runConfigList(cfg, flags, nil)
+	assert.NoError(t, err)
+
+	var values []configValue
+	err = json.Unmarshal(buf.Bytes(), &values)
+	assert.NoError(t, err)
+
+	byKey := map[string]configValue{}
+	for _, value := range values {
+		byKey[value.Key] = value
+	}
+	assert.Equal(t, sourceFile, byKey["token"].Source)
+	assert.Equal(t, []string{"1a11*************************aa1"}, byKey["token"].Values)
+	assert.Equal(t, sourceDefault, byKey["apibaseurl"].Source)
+	assert.Equal(t, []string{"http://example.com/api/v1"}, byKey["apibaseurl"].Values)
+	assert.Equal(t, sourceFile, byKey["tracks.go.ignorepatterns"].Source)
+
+	// The token is censored in the table too.
+	flags.Set("output", outputText)
+	buf.Reset()
+	err = runConfigList(cfg, flags, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "1a11*************************aa1")
+	assert.NotContains(t, buf.String(), "1a11111aaaa111aa1a11111a11111aa1")
+}
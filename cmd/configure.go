@@ -9,15 +9,12 @@ import (
 
 	"github.com/exercism/cli/api"
 	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/debug"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
-var (
-	viperConfig *viper.Viper
-)
-
 // configureCmd configures the command-line client with user-specific settings.
 var configureCmd = &cobra.Command{
 	Use:     "configure",
@@ -30,23 +27,59 @@ and tells the CLI about your setup so it puts things in the right
 places.
 
 You can also override certain default settings to suit your preferences.
+
+To use more than one account or site, keep their settings in separate
+profiles. Pass --profile=NAME to any command, or set the EXERCISM_PROFILE
+environment variable, to pick the profile to use. Running configure with
+a profile stores the settings in that profile.
+
+The EXERCISM_TOKEN, EXERCISM_WORKSPACE, and EXERCISM_API_BASE_URL
+environment variables override the configured settings for as long
+as they are set. They are never saved.
+
+By default the API token is stored in the config file, which only you
+can read. To keep it in the system keyring instead, call
+'exercism config set credentialstore keyring'. To hand it to a git-style
+credential helper, set credentialstore to helper:COMMAND.
+
+The token is censored when the configuration is shown. Pass
+--full-token to see all of it.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configuration := config.NewConfiguration()
+		return configure(cmd.Flags())
+	},
+}
 
-		viperConfig.AddConfigPath(configuration.Dir)
-		viperConfig.SetConfigName("user")
-		viperConfig.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = viperConfig.ReadInConfig()
-		configuration.UserViperConfig = viperConfig
+// configure reads the user config of the active profile and updates it.
+func configure(flags *pflag.FlagSet) error {
+	configuration := config.NewConfiguration()
 
-		return runConfigure(configuration, cmd.Flags())
-	},
+	v, err := config.NewUserViperConfig(configuration.UserDir)
+	if err != nil {
+		return err
+	}
+	configuration.UserViperConfig = v
+
+	return runConfigure(configuration, flags)
+}
+
+// configureToken saves a token, the same way as 'configure --token' does.
+func configureToken(token string) error {
+	flags := pflag.NewFlagSet("configure", pflag.ContinueOnError)
+	setupConfigureFlags(flags)
+	if err := flags.Set("token", token); err != nil {
+		return err
+	}
+	return configure(flags)
 }
 
 func runConfigure(configuration config.Configuration, flags *pflag.FlagSet) error {
 	cfg := configuration.UserViperConfig
+	// Values from the environment are used, but never saved.
+	config.BindEnv(cfg)
+	if err := config.LoadCredentials(cfg); err != nil {
+		return err
+	}
 
 	// Show the existing configuration and exit.
 	show, err := flags.GetBool("show")
@@ -54,8 +87,7 @@ func runConfigure(configuration config.Configuration, flags *pflag.FlagSet) erro
 		return err
 	}
 	if show {
-		printCurrentConfig(configuration)
-		return nil
+		return printCurrentConfig(configuration, flags)
 	}
 
 	// If the command is run 'bare' and we have no token,
@@ -65,10 +97,10 @@ func runConfigure(configuration config.Configuration, flags *pflag.FlagSet) erro
 		if baseURL != "" {
 			// If we have a base URL, then give the exact link.
 			tokenURL := config.InferSiteURL(baseURL) + "/my/settings"
-			return fmt.Errorf("There is no token configured. Find your token on %s, and call this command again with --token=<your-token>.", tokenURL)
+			return newError(errorNotConfigured, fmt.Errorf("There is no token configured. Find your token on %s, and call this command again with --token=<your-token>.", tokenURL))
 		}
 		// If we don't, then do our best.
-		return fmt.Errorf("There is no token configured. Find your token in your settings on the website, and call this command again with --token=<your-token>.")
+		return newError(errorNotConfigured, fmt.Errorf("There is no token configured. Find your token in your settings on the website, and call this command again with --token=<your-token>."))
 	}
 
 	// Determine the base API URL.
@@ -97,14 +129,14 @@ func runConfigure(configuration config.Configuration, flags *pflag.FlagSet) erro
 		if err != nil {
 			return err
 		}
+		client.Context = rootContext
 
-		ok, err := client.IsPingable()
-		if !ok || err != nil {
-			return fmt.Errorf("The base API URL '%s' cannot be reached.\n\n%s", baseURL, err)
+		if err := client.Ping(); err != nil {
+			return newError(errorNetwork, fmt.Errorf("The base API URL '%s' cannot be reached.\n\n%s", baseURL, err))
 		}
 	}
 	// Finally, configure the URL.
-	cfg.Set("apibaseurl", baseURL)
+	setSetting(cfg, flags, "api", "apibaseurl", baseURL)
 
 	// Determine the token.
 	token, err := flags.GetString("token")
@@ -120,7 +152,7 @@ func runConfigure(configuration config.Configuration, flags *pflag.FlagSet) erro
 
 	// If we don't have a token then explain how to set it and bail.
 	if token == "" {
-		return fmt.Errorf("There is no token configured. Find your token on %s, and call this command again with --token=<your-token>.", tokenURL)
+		return newError(errorNotConfigured, fmt.Errorf("There is no token configured. Find your token on %s, and call this command again with --token=<your-token>.", tokenURL))
 	}
 
 	// Verify that the token is valid.
@@ -129,17 +161,17 @@ func runConfigure(configuration config.Configuration, flags *pflag.FlagSet) erro
 		if err != nil {
 			return err
 		}
-		ok, err := client.TokenIsValid()
-		if err != nil {
+		client.Context = rootContext
+		if err := client.ValidateToken(); err != nil {
+			if _, ok := err.(*api.Error); ok {
+				return newError(errorUnauthorized, fmt.Errorf("The token '%s' is invalid. Find your token on %s.", token, tokenURL))
+			}
 			return err
 		}
-		if !ok {
-			return fmt.Errorf("The token '%s' is invalid. Find your token on %s.", token, tokenURL)
-		}
 	}
 
 	// Finally, configure the token.
-	cfg.Set("token", token)
+	setSetting(cfg, flags, "token", "token", token)
 
 	// Determine the workspace.
 	workspace, err := flags.GetString("workspace")
@@ -165,7 +197,7 @@ func runConfigure(configuration config.Configuration, flags *pflag.FlagSet) erro
 			  %s configure %s --workspace=PATH_TO_DIFFERENT_FOLDER
 			`
 
-			return errors.New(fmt.Sprintf(msg, workspace, BinaryName, commandify(flags)))
+			return newError(errorConflict, errors.New(fmt.Sprintf(msg, workspace, BinaryName, commandify(flags))))
 		}
 	}
 
@@ -188,32 +220,78 @@ func runConfigure(configuration config.Configuration, flags *pflag.FlagSet) erro
 			  %s configure %s --workspace=%s
 			`
 
-			return errors.New(fmt.Sprintf(msg, workspace, BinaryName, commandify(flags), workspace))
+			return newError(errorConflict, errors.New(fmt.Sprintf(msg, workspace, BinaryName, commandify(flags), workspace)))
 		}
 	}
 	// Configure the workspace.
-	cfg.Set("workspace", workspace)
+	setSetting(cfg, flags, "workspace", "workspace", workspace)
 
 	// Persist the new configuration.
 	if err := configuration.Save("user"); err != nil {
 		return err
 	}
-	printCurrentConfig(configuration)
-	return nil
+	return printCurrentConfig(configuration, flags)
 }
 
-func printCurrentConfig(configuration config.Configuration) {
-	w := tabwriter.NewWriter(Err, 0, 0, 2, ' ', 0)
-	defer w.Flush()
+// setSetting changes a user setting. Values that were passed as flags are always saved.
+// Others may have come from the environment, in which case they are not.
+func setSetting(v *viper.Viper, flags *pflag.FlagSet, flag, key, value string) {
+	if flags.Changed(flag) {
+		config.SetExplicitly(v, key, value)
+		return
+	}
+	v.Set(key, value)
+}
 
+// configureDocument is the JSON output of the configure command.
+type configureDocument struct {
+	ConfigDir  string `json:"config_dir"`
+	Profile    string `json:"profile"`
+	Token      string `json:"token"`
+	Workspace  string `json:"workspace"`
+	APIBaseURL string `json:"apibaseurl"`
+}
+
+func printCurrentConfig(configuration config.Configuration, flags *pflag.FlagSet) error {
 	v := configuration.UserViperConfig
 
+	fullToken, err := flags.GetBool("full-token")
+	if err != nil {
+		return err
+	}
+	token := censorToken(v.GetString("token"), fullToken)
+
+	if wantsJSON(flags) {
+		return writeJSON(Out, configureDocument{
+			ConfigDir:  configuration.Dir,
+			Profile:    configuration.Profile,
+			Token:      token,
+			Workspace:  v.GetString("workspace"),
+			APIBaseURL: v.GetString("apibaseurl"),
+		})
+	}
+
+	w := tabwriter.NewWriter(Err, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, fmt.Sprintf("Config dir:\t%s", configuration.Dir))
-	fmt.Fprintln(w, fmt.Sprintf("-t, --token\t%s", v.GetString("token")))
+	if configuration.Profile != "" && configuration.Profile != config.DefaultProfile {
+		fmt.Fprintln(w, fmt.Sprintf("Profile:\t%s", configuration.Profile))
+	}
+	fmt.Fprintln(w, fmt.Sprintf("-t, --token\t%s", token))
 	fmt.Fprintln(w, fmt.Sprintf("-w, --workspace\t%s", v.GetString("workspace")))
 	fmt.Fprintln(w, fmt.Sprintf("-a, --api\t%s", v.GetString("apibaseurl")))
 	fmt.Fprintln(w, "")
+	return nil
+}
+
+// censorToken masks the token, unless the full token was asked for.
+func censorToken(token string, full bool) string {
+	if full || token == "" {
+		return token
+	}
+	return debug.Redact(token)
 }
 
 func commandify(flags *pflag.FlagSet) string {
@@ -228,7 +306,6 @@ func commandify(flags *pflag.FlagSet) string {
 }
 
 func initConfigureCmd() {
-	viperConfig = viper.New()
 	setupConfigureFlags(configureCmd.Flags())
 }
 
@@ -237,6 +314,7 @@ func setupConfigureFlags(flags *pflag.FlagSet) {
 	flags.StringP("workspace", "w", "", "directory for exercism exercises")
 	flags.StringP("api", "a", "", "API base url")
 	flags.BoolP("show", "s", false, "show the current configuration")
+	flags.BoolP("full-token", "", false, "display the full token, censored by default")
 	flags.BoolP("no-verify", "", false, "skip online token authorization check")
 }
 
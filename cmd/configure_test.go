@@ -4,6 +4,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -81,13 +82,69 @@ func TestConfigureShow(t *testing.T) {
 	assert.Regexp(t, "configured.example", Err)
 	assert.NotRegexp(t, "override.example", Err)
 
-	assert.Regexp(t, "configured-token", Err)
+	assert.Regexp(t, `conf\*+ken`, Err)
+	assert.NotRegexp(t, "configured-token", Err)
 	assert.NotRegexp(t, "token-overrid", Err)
 
 	assert.Regexp(t, "configured-workspace", Err)
 	assert.NotRegexp(t, "workspace-override", Err)
 }
 
+func TestConfigureShowJSON(t *testing.T) {
+	oldOut := Out
+	defer func() {
+		Out = oldOut
+	}()
+
+	var buf bytes.Buffer
+	Out = &buf
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupConfigureFlags(flags)
+	flags.String("output", outputText, "")
+
+	v := viper.New()
+	v.Set("token", "configured-token")
+	v.Set("workspace", "configured-workspace")
+	v.Set("apibaseurl", "http://configured.example.com")
+
+	err := flags.Parse([]string{"--show", "--output", "json"})
+	assert.NoError(t, err)
+
+	cfg := config.Configuration{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+		Dir:             "/config",
+		Profile:         config.DefaultProfile,
+	}
+
+	err = runConfigure(cfg, flags)
+	assert.NoError(t, err)
+
+	var doc configureDocument
+	err = json.Unmarshal(buf.Bytes(), &doc)
+	assert.NoError(t, err)
+	assert.Equal(t, configureDocument{
+		ConfigDir:  "/config",
+		Profile:    config.DefaultProfile,
+		Token:      "conf*********ken",
+		Workspace:  "configured-workspace",
+		APIBaseURL: "http://configured.example.com",
+	}, doc)
+
+	// The full token is only shown when asked for.
+	err = flags.Parse([]string{"--full-token"})
+	assert.NoError(t, err)
+	buf.Reset()
+
+	err = runConfigure(cfg, flags)
+	assert.NoError(t, err)
+
+	err = json.Unmarshal(buf.Bytes(), &doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "configured-token", doc.Token)
+}
+
 func TestConfigureToken(t *testing.T) {
 	testCases := []struct {
 		desc       string
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/exercism/cli/fakeapi"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// devServerCmd runs a fake Exercism API for local development.
+var devServerCmd = &cobra.Command{
+	Use:   "dev-server",
+	Short: "Run a fake Exercism API on your machine.",
+	Long: `Run a fake Exercism API on your machine.
+
+The server answers the same calls as the real API, using the fixtures in
+the directory passed with --dir. The repository has a set of fixtures in
+fixtures/fakeapi. Submissions are saved in the submissions directory.
+
+Point the CLI at the server to work without a network:
+
+    exercism configure --api=http://localhost:3000 --token=fake-token
+
+Only requests that use the token passed with --token are authorized.
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDevServer(cmd.Flags())
+	},
+}
+
+func runDevServer(flags *pflag.FlagSet) error {
+	dir, err := flags.GetString("dir")
+	if err != nil {
+		return err
+	}
+	addr, err := flags.GetString("addr")
+	if err != nil {
+		return err
+	}
+	token, err := flags.GetString("token")
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return newError(errorValidation, fmt.Errorf("the fixtures directory '%s' does not exist", dir))
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	api := fakeapi.New(dir, token)
+	baseURL := fmt.Sprintf("http://%s", l.Addr())
+	fmt.Fprintf(Err, "\nServing the fixtures in %s\n\n", dir)
+	fmt.Fprintf(Err, "    %s configure --api=%s --token=%s\n\n", BinaryName, baseURL, api.Token)
+	fmt.Fprintf(Out, "%s\n", baseURL)
+
+	// Stop when the command is interrupted, e.g. with Ctrl-C.
+	server := &http.Server{Handler: logRequests(api)}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-rootContext.Done():
+			server.Shutdown(context.Background())
+		case <-done:
+		}
+	}()
+
+	err = server.Serve(l)
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// logRequests prints every request, and the status of its response.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r)
+		fmt.Fprintf(Err, "%s %s %d\n", r.Method, r.URL.RequestURI(), sw.status)
+	})
+}
+
+// statusWriter remembers the status of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func initDevServerCmd() {
+	devServerCmd.Flags().StringP("dir", "d", "fixtures/fakeapi", "directory with the fixtures to serve")
+	devServerCmd.Flags().StringP("addr", "a", "localhost:3000", "address to listen on")
+	devServerCmd.Flags().StringP("token", "t", fakeapi.DefaultToken, "the API token to accept")
+}
+
+func init() {
+	RootCmd.AddCommand(devServerCmd)
+	initDevServerCmd()
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevServerStopsWhenInterrupted(t *testing.T) {
+	oldOut, oldErr, oldContext := Out, Err, rootContext
+	Out, Err = ioutil.Discard, ioutil.Discard
+	ctx, cancel := context.WithCancel(context.Background())
+	rootContext = ctx
+	defer func() {
+		Out, Err, rootContext = oldOut, oldErr, oldContext
+	}()
+
+	dir, err := ioutil.TempDir("", "dev-server")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	flags.String("dir", dir, "")
+	flags.String("addr", "127.0.0.1:0", "")
+	flags.String("token", "", "")
+
+	done := make(chan error)
+	go func() { done <- runDevServer(flags) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server didn't stop")
+	}
+}
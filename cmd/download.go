@@ -1,14 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
 	"github.com/exercism/cli/api"
 	"github.com/exercism/cli/config"
@@ -16,6 +19,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	// downloadConcurrency is the number of solution files fetched in parallel.
+	downloadConcurrency = 4
+)
+
 // downloadCmd represents the download command
 var downloadCmd = &cobra.Command{
 	Use:     "download",
@@ -28,6 +36,11 @@ started working on it, the command will also download your
 latest solution.
 
 Download other people's solutions by providing the UUID.
+
+If a file already exists locally and differs from the downloaded
+version, the command shows the differences and asks what to do.
+Pass --force to always overwrite local files, or --keep-local
+to always keep them.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		token, err := cmd.Flags().GetString("token")
@@ -35,8 +48,7 @@ Download other people's solutions by providing the UUID.
 			return err
 		}
 		if token != "" {
-			RootCmd.SetArgs([]string{"configure", "--token", token})
-			if err := RootCmd.Execute(); err != nil {
+			if err := configureToken(token); err != nil {
 				return err
 			}
 		}
@@ -49,91 +61,68 @@ Download other people's solutions by providing the UUID.
 			return err
 		}
 		if uuid == "" && exercise == "" {
-			return errors.New("need an --exercise name or a solution --uuid")
+			return newError(errorValidation, errors.New("need an --exercise name or a solution --uuid"))
 		}
-		usrCfg, err := config.NewUserConfig()
+		policy, err := conflictPolicyFromFlags(cmd.Flags())
 		if err != nil {
 			return err
 		}
-
-		var slug string
-		if uuid == "" {
-			slug = "latest"
-		} else {
-			slug = uuid
-		}
-		url := fmt.Sprintf("%s/solutions/%s", usrCfg.APIBaseURL, slug)
-
-		client, err := api.NewClient(usrCfg.Token, usrCfg.APIBaseURL)
+		cfg, err := config.Load()
 		if err != nil {
 			return err
 		}
+		usrCfg := cfg.UserConfig
 
-		req, err := client.NewRequest("GET", url, nil)
+		client, err := api.NewClient(usrCfg.Token, usrCfg.APIBaseURL)
 		if err != nil {
 			return err
 		}
+		client.Context = rootContext
 
 		track, err := cmd.Flags().GetString("track")
 		if err != nil {
 			return err
 		}
 
+		var remote *api.Solution
 		if uuid == "" {
-			q := req.URL.Query()
-			q.Add("exercise_id", exercise)
-			if track != "" {
-				q.Add("track_id", track)
-			}
-			req.URL.RawQuery = q.Encode()
-		}
-
-		res, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-
-		var payload downloadPayload
-		defer res.Body.Close()
-		if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
-			return fmt.Errorf("unable to parse API response - %s", err)
+			remote, err = client.GetLatestSolution(track, exercise)
+		} else {
+			remote, err = client.GetSolution(uuid)
 		}
-
-		if res.StatusCode == http.StatusUnauthorized {
+		if apiErr, ok := err.(*api.Error); ok && apiErr.StatusCode == http.StatusUnauthorized {
 			siteURL := config.InferSiteURL(usrCfg.APIBaseURL)
-			return fmt.Errorf("unauthorized request. Please run the configure command. You can find your API token at %s/my/settings", siteURL)
+			return newError(errorUnauthorized, fmt.Errorf("unauthorized request. Please run the configure command. You can find your API token at %s/my/settings", siteURL))
 		}
-
-		if res.StatusCode != http.StatusOK {
-			switch payload.Error.Type {
-			case "track_ambiguous":
-				return fmt.Errorf("%s: %s", payload.Error.Message, strings.Join(payload.Error.PossibleTrackIDs, ", "))
-			default:
-				return errors.New(payload.Error.Message)
-			}
+		if err != nil {
+			return err
 		}
 
 		solution := workspace.Solution{
-			AutoApprove: payload.Solution.Exercise.AutoApprove,
-			Track:       payload.Solution.Exercise.Track.ID,
-			Exercise:    payload.Solution.Exercise.ID,
-			ID:          payload.Solution.ID,
-			URL:         payload.Solution.URL,
-			Handle:      payload.Solution.User.Handle,
-			IsRequester: payload.Solution.User.IsRequester,
+			AutoApprove: remote.Exercise.AutoApprove,
+			Track:       remote.Exercise.Track.ID,
+			Exercise:    remote.Exercise.ID,
+			ID:          remote.ID,
+			URL:         remote.URL,
+			Handle:      remote.User.Handle,
+			IsRequester: remote.User.IsRequester,
 		}
 
 		dir := filepath.Join(usrCfg.Workspace, solution.Track)
 		os.MkdirAll(dir, os.FileMode(0755))
 
+		root, err := workspace.New(usrCfg.Workspace)
+		if err != nil {
+			return err
+		}
 		var ws workspace.Workspace
 		if solution.IsRequester {
-			ws, err = workspace.New(dir)
+			ws, err = root.In(solution.Track)
 			if err != nil {
 				return err
 			}
 		} else {
-			ws, err = workspace.New(filepath.Join(usrCfg.Workspace, "users", solution.Handle, solution.Track))
+			ws, err = root.In("users", solution.Handle, solution.Track)
 			if err != nil {
 				return err
 			}
@@ -143,86 +132,329 @@ Download other people's solutions by providing the UUID.
 		if err != nil {
 			return err
 		}
+		local, _ := workspace.NewSolution(dir)
+		solution.SubmittedAt = lastSubmitted(remote, local)
 
+		// Remember whether the directory is new, so that an interrupted download can be undone.
+		_, err = os.Stat(dir)
+		created := os.IsNotExist(err)
 		os.MkdirAll(dir, os.FileMode(0755))
 
-		err = solution.Write(dir)
+		pending, err := workspace.NewPending(dir)
 		if err != nil {
 			return err
 		}
+		if len(pending.Files) > 0 {
+			fmt.Fprintf(Err, "\nResuming %d file(s) that failed to download last time.\n", len(pending.Files))
+		}
 
-		for _, file := range payload.Solution.Files {
-			url := fmt.Sprintf("%s%s", payload.Solution.FileDownloadBaseURL, file)
-			req, err := client.NewRequest("GET", url, nil)
-			if err != nil {
-				return err
-			}
-
-			res, err := client.Do(req)
-			if err != nil {
-				return err
-			}
-			defer res.Body.Close()
+		results := downloadFiles(client, remote.FileDownloadBaseURL, dir, remote.Files)
 
-			if res.StatusCode != http.StatusOK {
-				// TODO: deal with it
-				continue
-			}
-			// Don't bother with empty files.
-			if res.Header.Get("Content-Length") == "0" {
+		resolver := newConflictResolver(policy)
+		for i := range results {
+			if results[i].tmp == "" || interrupted() {
 				continue
 			}
+			results[i].status, results[i].err = resolver.place(results[i])
+		}
 
-			// TODO: if there's a collision, interactively resolve (show diff, ask if overwrite).
-			// TODO: handle --force flag to overwrite without asking.
-			relativePath := filepath.FromSlash(file)
-			dir := filepath.Join(solution.Dir, filepath.Dir(relativePath))
-			os.MkdirAll(dir, os.FileMode(0755))
+		if interrupted() {
+			return abandonDownload(&solution, dir, created, results)
+		}
 
-			f, err := os.Create(filepath.Join(solution.Dir, relativePath))
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-			_, err = io.Copy(f, res.Body)
-			if err != nil {
-				return err
+		// Files that could not be downloaded are retried next time.
+		// Conflicts that weren't resolved are left to the person.
+		pending = &workspace.Pending{}
+		var failure error
+		var conflicts []string
+		for _, result := range results {
+			switch {
+			case result.err == nil:
+			case errorKind(result.err) == errorConflict:
+				conflicts = append(conflicts, result.file)
+			default:
+				pending.Add(result.file)
+				if failure == nil {
+					failure = result.err
+				}
 			}
 		}
+		if err := pending.Write(dir); err != nil {
+			return err
+		}
+
+		// The solution is only recorded once its files are in place.
+		if err := solution.Write(dir); err != nil {
+			return err
+		}
+		if err := ws.UpdateIndex(&solution); err != nil {
+			return err
+		}
+
+		printDownloadSummary(results)
+
+		if len(pending.Files) > 0 {
+			msg := `
+
+    %d file(s) could not be downloaded. They have been recorded in
+
+        %s
+
+    Run the command again to retry.
+			`
+			return newError(errorKind(failure), fmt.Errorf(msg, len(pending.Files), solution.Dir))
+		}
+		if len(conflicts) > 0 {
+			msg := `
+
+    %d file(s) differ from the downloaded copies, and were left alone:
+
+        %s
+
+    Run the command again with --force or --keep-local to decide up front.
+			`
+			return newError(errorConflict, fmt.Errorf(msg, len(conflicts), strings.Join(conflicts, "\n        ")))
+		}
+
+		if wantsJSON(cmd.Flags()) {
+			return writeJSON(Out, newDownloadDocument(&solution, results))
+		}
 		fmt.Fprintf(Err, "\nDownloaded to\n")
 		fmt.Fprintf(Out, "%s\n", solution.Dir)
 		return nil
 	},
 }
 
-type downloadPayload struct {
-	Solution struct {
-		ID   string `json:"id"`
-		URL  string `json:"url"`
-		User struct {
-			Handle      string `json:"handle"`
-			IsRequester bool   `json:"is_requester"`
-		} `json:"user"`
-		Exercise struct {
-			ID              string `json:"id"`
-			InstructionsURL string `json:"instructions_url"`
-			AutoApprove     bool   `json:"auto_approve"`
-			Track           struct {
-				ID       string `json:"id"`
-				Language string `json:"language"`
-			} `json:"track"`
-		} `json:"exercise"`
-		FileDownloadBaseURL string   `json:"file_download_base_url"`
-		Files               []string `json:"files"`
-		Iteration           struct {
-			SubmittedAt *string `json:"submitted_at"`
-		}
-	} `json:"solution"`
-	Error struct {
-		Type             string   `json:"type"`
-		Message          string   `json:"message"`
-		PossibleTrackIDs []string `json:"possible_track_ids"`
-	} `json:"error,omitempty"`
+// abandonDownload cleans up after an interrupted download.
+// Files that were downloaded but not moved into place are removed.
+// If nothing was placed in a new solution directory, the directory is removed too.
+// Otherwise the files that are missing are recorded as pending,
+// so that downloading the solution again picks up where this left off.
+func abandonDownload(solution *workspace.Solution, dir string, created bool, results []downloadResult) error {
+	pending := &workspace.Pending{}
+	for _, result := range results {
+		if result.err == nil && result.status != "" {
+			continue
+		}
+		if result.tmp != "" {
+			os.Remove(result.tmp)
+		}
+		pending.Add(result.file)
+	}
+
+	if created && len(pending.Files) == len(results) {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		return newError(errorInterrupted, errors.New("the download was interrupted, nothing was saved"))
+	}
+
+	if err := pending.Write(dir); err != nil {
+		return err
+	}
+	if err := solution.Write(dir); err != nil {
+		return err
+	}
+	msg := `
+
+    The download was interrupted. %d file(s) still need to be downloaded.
+    They have been recorded in
+
+        %s
+
+    Run the command again to resume.
+	`
+	return newError(errorInterrupted, fmt.Errorf(msg, len(pending.Files), dir))
+}
+
+// downloadDocument is the JSON output of the download command.
+type downloadDocument struct {
+	ID          string                 `json:"id"`
+	Track       string                 `json:"track"`
+	Exercise    string                 `json:"exercise"`
+	Handle      string                 `json:"handle"`
+	IsRequester bool                   `json:"is_requester"`
+	URL         string                 `json:"url"`
+	Dir         string                 `json:"dir"`
+	Files       []downloadFileDocument `json:"files"`
+}
+
+type downloadFileDocument struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+func newDownloadDocument(solution *workspace.Solution, results []downloadResult) downloadDocument {
+	doc := downloadDocument{
+		ID:          solution.ID,
+		Track:       solution.Track,
+		Exercise:    solution.Exercise,
+		Handle:      solution.Handle,
+		IsRequester: solution.IsRequester,
+		URL:         solution.URL,
+		Dir:         solution.Dir,
+		Files:       make([]downloadFileDocument, 0, len(results)),
+	}
+	for _, result := range results {
+		doc.Files = append(doc.Files, downloadFileDocument{Name: result.file, Status: result.status})
+	}
+	return doc
+}
+
+// downloadResult is the outcome of fetching a single solution file.
+type downloadResult struct {
+	file   string
+	path   string
+	tmp    string
+	status string
+	err    error
+}
+
+// downloadFiles fetches the solution files with a bounded pool of workers.
+// Each file is written to a temporary file first. It is only moved into
+// place once every download has finished, so that any conflicts with
+// local files can be resolved one at a time.
+func downloadFiles(client *api.Client, baseURL, dir string, files []string) []downloadResult {
+	results := make([]downloadResult, len(files))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	workers := downloadConcurrency
+	if workers > len(files) {
+		workers = len(files)
+	}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				results[n] = downloadFileWithRetry(client, baseURL, dir, files[n])
+			}
+		}()
+	}
+	for n := range files {
+		jobs <- n
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// downloadFileWithRetry fetches a file, trying again after transient failures.
+// The whole download is retried, not just the request, since the connection
+// may also drop while the body is being read.
+func downloadFileWithRetry(client *api.Client, baseURL, dir, file string) downloadResult {
+	// The retries happen here, so the client shouldn't retry on its own.
+	once := *client
+	once.Retry.MaxAttempts = 1
+
+	for attempt := 1; ; attempt++ {
+		result, transient := downloadFile(&once, baseURL, dir, file)
+		if result.err == nil || !transient || attempt >= client.Retry.MaxAttempts {
+			return result
+		}
+		if err := client.Backoff(attempt); err != nil {
+			return result
+		}
+	}
+}
+
+// downloadFile fetches a single file into a temporary file
+// next to its destination in the solution directory.
+// It reports whether a failure is worth retrying.
+func downloadFile(client *api.Client, baseURL, dir, file string) (downloadResult, bool) {
+	result := downloadResult{file: file}
+
+	url := fmt.Sprintf("%s%s", baseURL, file)
+	req, err := client.NewRequest("GET", url, nil)
+	if err != nil {
+		result.err = err
+		return result, false
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		result.err = err
+		return result, !interrupted()
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		// Report it like the API would, so that the kind of failure is known.
+		result.err = &api.Error{StatusCode: res.StatusCode, Message: res.Status}
+		return result, res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+	}
+	// Don't bother with empty files.
+	if res.Header.Get("Content-Length") == "0" {
+		result.status = "skipped (empty)"
+		return result, false
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+		result.err = err
+		return result, false
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), fmt.Sprintf(".%s.download-", filepath.Base(path)))
+	if err != nil {
+		result.err = err
+		return result, false
+	}
+	name := tmp.Name()
+	onForcedExit(func() { os.Remove(name) })
+	_, copyErr := io.Copy(tmp, res.Body)
+	err = tmp.Close()
+	if copyErr != nil {
+		err = copyErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		result.err = err
+		// A connection that drops halfway is worth another try.
+		return result, copyErr != nil && !interrupted()
+	}
+
+	result.path = path
+	result.tmp = tmp.Name()
+	return result, false
+}
+
+// lastSubmitted is when the solution was last submitted, whether that was
+// from this machine, as recorded in the local metadata, or somewhere else.
+func lastSubmitted(remote *api.Solution, local *workspace.Solution) *time.Time {
+	var at *time.Time
+	if remote.Iteration.SubmittedAt != nil {
+		// RFC 3339 allows a lowercase "t" and "z", which time.Parse doesn't.
+		if t, err := time.Parse(time.RFC3339, strings.ToUpper(*remote.Iteration.SubmittedAt)); err == nil {
+			at = &t
+		}
+	}
+	if local != nil && local.ID == remote.ID && local.SubmittedAt != nil {
+		if at == nil || local.SubmittedAt.After(*at) {
+			at = local.SubmittedAt
+		}
+	}
+	return at
+}
+
+func printDownloadSummary(results []downloadResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(Err, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "")
+	for _, result := range results {
+		status := result.status
+		if result.err != nil {
+			status = fmt.Sprintf("failed (%s)", result.err)
+		}
+		fmt.Fprintf(w, "    %s\t%s\n", result.file, status)
+	}
 }
 
 func initDownloadCmd() {
@@ -230,6 +462,8 @@ func initDownloadCmd() {
 	downloadCmd.Flags().StringP("track", "t", "", "the track ID")
 	downloadCmd.Flags().StringP("exercise", "e", "", "the exercise slug")
 	downloadCmd.Flags().StringP("token", "k", "", "authentication token used to connect to the site")
+	downloadCmd.Flags().BoolP("force", "f", false, "overwrite local files that differ from the downloaded ones")
+	downloadCmd.Flags().BoolP("keep-local", "", false, "keep local files that differ from the downloaded ones")
 }
 
 func init() {
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/exercism/cli/comms"
+	"github.com/exercism/cli/workspace"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/pflag"
+)
+
+// conflictPolicy decides what happens when a downloaded file
+// differs from the one that is already on disk.
+type conflictPolicy int
+
+const (
+	// conflictAsk shows a diff and asks what to do.
+	conflictAsk conflictPolicy = iota
+	// conflictOverwrite replaces the local file.
+	conflictOverwrite
+	// conflictKeepLocal leaves the local file alone.
+	conflictKeepLocal
+)
+
+// maxConflictPrompts limits how often we re-ask for a valid choice.
+const maxConflictPrompts = 3
+
+func conflictPolicyFromFlags(flags *pflag.FlagSet) (conflictPolicy, error) {
+	force, err := flags.GetBool("force")
+	if err != nil {
+		return conflictAsk, err
+	}
+	keepLocal, err := flags.GetBool("keep-local")
+	if err != nil {
+		return conflictAsk, err
+	}
+	if force && keepLocal {
+		return conflictAsk, newError(errorValidation, errors.New("--force and --keep-local cannot be used together"))
+	}
+	if force {
+		return conflictOverwrite, nil
+	}
+	if keepLocal {
+		return conflictKeepLocal, nil
+	}
+	return conflictAsk, nil
+}
+
+// conflictChoice is an option offered when resolving a conflict.
+type conflictChoice struct {
+	policy      conflictPolicy
+	saveBeside  bool
+	description string
+}
+
+func (c conflictChoice) String() string {
+	return c.description
+}
+
+// conflictResolver moves downloaded files into place,
+// resolving any conflicts with existing local files.
+type conflictResolver struct {
+	policy conflictPolicy
+	in     *bufio.Reader
+}
+
+func newConflictResolver(policy conflictPolicy) *conflictResolver {
+	// Share a single buffered reader across prompts,
+	// so that no input gets lost between questions.
+	return &conflictResolver{
+		policy: policy,
+		in:     bufio.NewReader(In),
+	}
+}
+
+// place moves a downloaded file to its destination.
+// It returns a short description of what happened.
+func (r *conflictResolver) place(result downloadResult) (string, error) {
+	local, err := ioutil.ReadFile(result.path)
+	if os.IsNotExist(err) {
+		return "downloaded", r.accept(result)
+	}
+	if err != nil {
+		os.Remove(result.tmp)
+		return "", err
+	}
+
+	incoming, err := ioutil.ReadFile(result.tmp)
+	if err != nil {
+		os.Remove(result.tmp)
+		return "", err
+	}
+
+	if bytes.Equal(local, incoming) {
+		return "unchanged", os.Remove(result.tmp)
+	}
+
+	choice := conflictChoice{policy: r.policy}
+	if r.policy == conflictAsk {
+		choice, err = r.ask(result.file, local, incoming)
+		if err != nil {
+			os.Remove(result.tmp)
+			return "", err
+		}
+	}
+
+	switch {
+	case choice.saveBeside:
+		path := result.path + workspace.IncomingSuffix
+		if err := os.Rename(result.tmp, path); err != nil {
+			os.Remove(result.tmp)
+			return "", err
+		}
+		return fmt.Sprintf("kept local copy, saved download as %s", result.file+workspace.IncomingSuffix), nil
+	case choice.policy == conflictOverwrite:
+		return "overwrote local copy", r.accept(result)
+	default:
+		return "kept local copy", os.Remove(result.tmp)
+	}
+}
+
+func (r *conflictResolver) accept(result downloadResult) error {
+	if err := os.Rename(result.tmp, result.path); err != nil {
+		os.Remove(result.tmp)
+		return err
+	}
+	return nil
+}
+
+func (r *conflictResolver) ask(file string, local, incoming []byte) (conflictChoice, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(local)),
+		B:        difflib.SplitLines(string(incoming)),
+		FromFile: fmt.Sprintf("%s (local)", file),
+		ToFile:   fmt.Sprintf("%s (downloaded)", file),
+		Context:  3,
+	})
+	if err != nil {
+		return conflictChoice{}, err
+	}
+	fmt.Fprintf(Err, "\nYour local copy of %s differs from the downloaded one.\n\n%s", file, diff)
+
+	selection := comms.Selection{
+		Items: []fmt.Stringer{
+			conflictChoice{policy: conflictKeepLocal, description: "keep my local copy"},
+			conflictChoice{policy: conflictOverwrite, description: "overwrite it with the downloaded copy"},
+			conflictChoice{policy: conflictKeepLocal, saveBeside: true, description: fmt.Sprintf("keep my local copy, and save the downloaded one as %s", file+workspace.IncomingSuffix)},
+		},
+		Reader: r.in,
+		Writer: Err,
+	}
+	prompt := `
+What would you like to do?
+Type the number of the one you want to select.
+
+%s
+> `
+	for i := 0; i < maxConflictPrompts; i++ {
+		option, err := selection.Pick(prompt)
+		if err != nil {
+			fmt.Fprintln(Err, err)
+			continue
+		}
+		if choice, ok := option.(conflictChoice); ok {
+			return choice, nil
+		}
+	}
+	return conflictChoice{}, newError(errorConflict, fmt.Errorf("no choice was made for %s, pass --force or --keep-local to decide up front", file))
+}
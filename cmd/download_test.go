@@ -1,15 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/exercism/cli/api"
 	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/workspace"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -85,7 +91,7 @@ func TestDownload(t *testing.T) {
 		{
 			desc:     "It creates the .solution.json file.",
 			path:     filepath.Join(cmdTest.TmpDir, "bogus-track", "bogus-exercise", ".solution.json"),
-			contents: `{"track":"bogus-track","exercise":"bogus-exercise","id":"bogus-id","url":"","handle":"alice","is_requester":true,"auto_approve":false}`,
+			contents: `{"track":"bogus-track","exercise":"bogus-exercise","id":"bogus-id","url":"","handle":"alice","is_requester":true,"submitted_at":"2017-08-21T10:11:12.13Z","auto_approve":false}`,
 		},
 	}
 
@@ -104,6 +110,431 @@ func TestDownload(t *testing.T) {
 	assert.True(t, os.IsNotExist(err), "It should not write the file if empty.")
 }
 
+func TestDownloadRetriesAndRecordsFailures(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldRetry := api.DefaultRetryPolicy
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	api.DefaultRetryPolicy.BaseDelay = 0
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		api.DefaultRetryPolicy = oldRetry
+	}()
+
+	cmdTest := &CommandTest{
+		Cmd:    downloadCmd,
+		InitFn: initDownloadCmd,
+		Args:   []string{"fakeapp", "download", "--exercise=bogus-exercise"},
+	}
+	cmdTest.Setup(t)
+	defer cmdTest.Teardown(t)
+
+	var flakyCalls int32
+	broken := true
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/file-1.txt", func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt, succeed on the retry.
+		if atomic.AddInt32(&flakyCalls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "this is file 1")
+	})
+	mux.HandleFunc("/subdir/file-2.txt", func(w http.ResponseWriter, r *http.Request) {
+		if broken {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "this is file 2")
+	})
+	mux.HandleFunc("/file-3.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "")
+	})
+	payloadBody := fmt.Sprintf(payloadTemplate, server.URL+"/", "file-1.txt", "subdir/file-2.txt", "file-3.txt")
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, payloadBody)
+	})
+
+	err := writeFakeUserConfigSettings(cmdTest.TmpDir, server.URL)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(cmdTest.TmpDir, "bogus-track", "bogus-exercise")
+
+	err = cmdTest.App.Execute()
+	assert.Equal(t, errorNotFound, errorKind(err))
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "file-1.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "this is file 1", string(b))
+
+	pending, err := workspace.NewPending(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"subdir/file-2.txt"}, pending.Files)
+
+	// The next run picks up the missing file and clears the record.
+	broken = false
+	err = cmdTest.App.Execute()
+	assert.NoError(t, err)
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "subdir", "file-2.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "this is file 2", string(b))
+
+	pending, err = workspace.NewPending(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, pending.Files)
+
+	// No temporary files are left behind.
+	infos, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	for _, info := range infos {
+		assert.NotContains(t, info.Name(), ".download-")
+	}
+}
+
+func TestDownloadRetriesDroppedConnections(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldRetry := api.DefaultRetryPolicy
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	api.DefaultRetryPolicy.BaseDelay = 0
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		api.DefaultRetryPolicy = oldRetry
+	}()
+
+	cmdTest := &CommandTest{
+		Cmd:    downloadCmd,
+		InitFn: initDownloadCmd,
+		Args:   []string{"fakeapp", "download", "--exercise=bogus-exercise"},
+	}
+	cmdTest.Setup(t)
+	defer cmdTest.Teardown(t)
+
+	var calls int32
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	content := "this is file 1"
+	mux.HandleFunc("/file-1.txt", func(w http.ResponseWriter, r *http.Request) {
+		// The first time, hang up halfway through the body.
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+			fmt.Fprint(w, content[:4])
+			panic(http.ErrAbortHandler)
+		}
+		fmt.Fprint(w, content)
+	})
+	for _, file := range []string{"/subdir/file-2.txt", "/file-3.txt"} {
+		mux.HandleFunc(file, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "content")
+		})
+	}
+	payloadBody := fmt.Sprintf(payloadTemplate, server.URL+"/", "file-1.txt", "subdir/file-2.txt", "file-3.txt")
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, payloadBody)
+	})
+
+	err := writeFakeUserConfigSettings(cmdTest.TmpDir, server.URL)
+	assert.NoError(t, err)
+
+	err = cmdTest.App.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	b, err := ioutil.ReadFile(filepath.Join(cmdTest.TmpDir, "bogus-track", "bogus-exercise", "file-1.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(b))
+}
+
+func TestDownloadInterrupted(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldContext := rootContext
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		rootContext = oldContext
+	}()
+
+	cmdTest := &CommandTest{
+		Cmd:    downloadCmd,
+		InitFn: initDownloadCmd,
+		Args:   []string{"fakeapp", "download", "--exercise=bogus-exercise"},
+	}
+	cmdTest.Setup(t)
+	defer cmdTest.Teardown(t)
+
+	var cancel context.CancelFunc
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	for _, file := range []string{"/file-1.txt", "/subdir/file-2.txt"} {
+		content := fmt.Sprintf("this is %s", file)
+		mux.HandleFunc(file, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, content)
+		})
+	}
+	mux.HandleFunc("/file-3.txt", func(w http.ResponseWriter, r *http.Request) {
+		// Hit Ctrl-C in the middle of the download.
+		if cancel != nil {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, "this is file 3")
+	})
+	payloadBody := fmt.Sprintf(payloadTemplate, server.URL+"/", "file-1.txt", "subdir/file-2.txt", "file-3.txt")
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, payloadBody)
+	})
+
+	err := writeFakeUserConfigSettings(cmdTest.TmpDir, server.URL)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(cmdTest.TmpDir, "bogus-track", "bogus-exercise")
+
+	// A new solution directory is removed again.
+	rootContext, cancel = context.WithCancel(context.Background())
+	err = cmdTest.App.Execute()
+	assert.Equal(t, errorInterrupted, errorKind(err))
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	// An existing one is left with a record of what is missing.
+	rootContext, cancel = context.Background(), nil
+	err = cmdTest.App.Execute()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(filepath.Join(dir, "file-3.txt")))
+
+	rootContext, cancel = context.WithCancel(context.Background())
+	err = cmdTest.App.Execute()
+	assert.Equal(t, errorInterrupted, errorKind(err))
+
+	pending, err := workspace.NewPending(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"file-1.txt", "file-3.txt", "subdir/file-2.txt"}, pending.Files)
+	solution, err := workspace.NewSolution(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "bogus-id", solution.ID)
+
+	// No temporary files are left behind.
+	infos, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	assert.Equal(t, []string{".pending.json", ".solution.json", "file-1.txt", "subdir"}, names)
+
+	// The next run resumes.
+	rootContext, cancel = context.Background(), nil
+	err = cmdTest.App.Execute()
+	assert.NoError(t, err)
+
+	pending, err = workspace.NewPending(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, pending.Files)
+	b, err := ioutil.ReadFile(filepath.Join(dir, "file-3.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "this is file 3", string(b))
+}
+
+func TestDownloadConflicts(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldIn := In
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		In = oldIn
+	}()
+
+	testCases := []struct {
+		desc     string
+		flags    []string
+		input    string
+		local    string
+		incoming string
+		kind     string
+	}{
+		{
+			desc:  "it keeps local files with --keep-local",
+			flags: []string{"--keep-local"},
+			local: "my local edits",
+		},
+		{
+			desc:  "it overwrites local files with --force",
+			flags: []string{"--force"},
+			local: "this is file 1",
+		},
+		{
+			desc:  "it asks, and keeps the local file",
+			input: "1\n",
+			local: "my local edits",
+		},
+		{
+			desc:  "it asks, and overwrites the local file",
+			input: "2\n",
+			local: "this is file 1",
+		},
+		{
+			desc:     "it asks, and saves the download beside the local file",
+			input:    "3\n",
+			local:    "my local edits",
+			incoming: "this is file 1",
+		},
+		{
+			desc:  "it asks again after an invalid choice",
+			input: "7\n2\n",
+			local: "this is file 1",
+		},
+		{
+			desc:  "it reports a conflict without a valid choice",
+			input: "7\n8\n9\n",
+			local: "my local edits",
+			kind:  errorConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmdTest := &CommandTest{
+				Cmd:    downloadCmd,
+				InitFn: initDownloadCmd,
+				Args:   append([]string{"fakeapp", "download", "--exercise=bogus-exercise"}, tc.flags...),
+			}
+			cmdTest.Setup(t)
+			defer cmdTest.Teardown(t)
+
+			In = strings.NewReader(tc.input)
+
+			mockServer := makeMockServer()
+			defer mockServer.Close()
+
+			err := writeFakeUserConfigSettings(cmdTest.TmpDir, mockServer.URL)
+			assert.NoError(t, err)
+
+			dir := filepath.Join(cmdTest.TmpDir, "bogus-track", "bogus-exercise")
+			os.MkdirAll(filepath.Join(dir, "subdir"), os.FileMode(0755))
+			solution := &workspace.Solution{
+				ID:          "bogus-id",
+				Track:       "bogus-track",
+				Exercise:    "bogus-exercise",
+				IsRequester: true,
+			}
+			err = solution.Write(dir)
+			assert.NoError(t, err)
+
+			file1 := filepath.Join(dir, "file-1.txt")
+			err = ioutil.WriteFile(file1, []byte("my local edits"), os.FileMode(0644))
+			assert.NoError(t, err)
+			// An identical file is not a conflict.
+			file2 := filepath.Join(dir, "subdir", "file-2.txt")
+			err = ioutil.WriteFile(file2, []byte("this is file 2"), os.FileMode(0644))
+			assert.NoError(t, err)
+
+			err = cmdTest.App.Execute()
+			if tc.kind == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tc.kind, errorKind(err))
+			}
+			// Conflicts are not retried as failed downloads.
+			pending, err := workspace.NewPending(dir)
+			assert.NoError(t, err)
+			assert.Empty(t, pending.Files)
+
+			b, err := ioutil.ReadFile(file1)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.local, string(b))
+
+			b, err = ioutil.ReadFile(file1 + workspace.IncomingSuffix)
+			if tc.incoming == "" {
+				assert.True(t, os.IsNotExist(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.incoming, string(b))
+			}
+		})
+	}
+}
+
+func TestDownloadKeepsLocalSubmissionTime(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	cmdTest := &CommandTest{
+		Cmd:    downloadCmd,
+		InitFn: initDownloadCmd,
+		Args:   []string{"fakeapp", "download", "--exercise=bogus-exercise", "--force"},
+	}
+	cmdTest.Setup(t)
+	defer cmdTest.Teardown(t)
+
+	mockServer := makeMockServer()
+	defer mockServer.Close()
+
+	err := writeFakeUserConfigSettings(cmdTest.TmpDir, mockServer.URL)
+	assert.NoError(t, err)
+
+	// Submitted from this machine after the latest iteration the API knows about.
+	submittedAt := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	dir := filepath.Join(cmdTest.TmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	solution := &workspace.Solution{
+		ID:          "bogus-id",
+		Track:       "bogus-track",
+		Exercise:    "bogus-exercise",
+		IsRequester: true,
+		SubmittedAt: &submittedAt,
+	}
+	err = solution.Write(dir)
+	assert.NoError(t, err)
+
+	err = cmdTest.App.Execute()
+	assert.NoError(t, err)
+
+	solution, err = workspace.NewSolution(dir)
+	assert.NoError(t, err)
+	if assert.NotNil(t, solution.SubmittedAt) {
+		assert.True(t, submittedAt.Equal(*solution.SubmittedAt))
+	}
+}
+
+func TestDownloadForceAndKeepLocal(t *testing.T) {
+	cmdTest := &CommandTest{
+		Cmd:    downloadCmd,
+		InitFn: initDownloadCmd,
+		Args:   []string{"fakeapp", "download", "--exercise=bogus-exercise", "--force", "--keep-local"},
+	}
+	cmdTest.Setup(t)
+	cmdTest.App.SetOutput(ioutil.Discard)
+	defer cmdTest.Teardown(t)
+
+	err := cmdTest.App.Execute()
+	assert.EqualError(t, err, "--force and --keep-local cannot be used together")
+}
+
 func writeFakeUserConfigSettings(tmpDirPath, serverURL string) error {
 	userCfg := config.NewEmptyUserConfig()
 	userCfg.Workspace = tmpDirPath
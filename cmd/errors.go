@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Kinds of errors. Each kind has its own exit code,
+// so that scripts can tell why a command failed.
+// The kinds and exit codes are part of the interface of the CLI.
+// Don't change them, only add new ones.
+const (
+	// errorGeneric is anything that isn't covered by a more specific kind.
+	errorGeneric = "error"
+	// errorValidation means that the arguments, flags, or settings don't make sense.
+	errorValidation = "validation"
+	// errorUnauthorized means that the API rejected the token.
+	errorUnauthorized = "unauthorized"
+	// errorNotConfigured means that a required setting, like the token, is missing.
+	errorNotConfigured = "not_configured"
+	// errorNotFound means that an exercise, solution, or file doesn't exist.
+	errorNotFound = "not_found"
+	// errorAmbiguousTrack means that the exercise exists on more than one track.
+	errorAmbiguousTrack = "ambiguous_track"
+	// errorNetwork means that the API couldn't be reached.
+	// Server errors (5xx) are network errors too: like a dropped connection,
+	// they are on the API's side, and trying again later may well work.
+	errorNetwork = "network"
+	// errorConflict means that local files are in the way.
+	errorConflict = "conflict"
+	// errorInterrupted means that the command was interrupted, e.g. with Ctrl-C.
+	errorInterrupted = "interrupted"
+)
+
+// exitCodes are the exit codes of each kind of error.
+var exitCodes = map[string]int{
+	errorGeneric:        1,
+	errorValidation:     2,
+	errorUnauthorized:   3,
+	errorNotConfigured:  4,
+	errorNotFound:       5,
+	errorAmbiguousTrack: 6,
+	errorNetwork:        7,
+	errorConflict:       8,
+	// Shells report commands that were stopped with Ctrl-C this way.
+	errorInterrupted: 130,
+}
+
+// cmdError is an error of a known kind.
+type cmdError struct {
+	kind string
+	err  error
+}
+
+func (e *cmdError) Error() string {
+	return e.err.Error()
+}
+
+// Cause is the error that was marked.
+func (e *cmdError) Cause() error {
+	return e.err
+}
+
+// causer is an error that wraps another error, e.g. to add context to its message.
+type causer interface {
+	Cause() error
+}
+
+// newError marks an error as being of the given kind.
+func newError(kind string, err error) error {
+	return &cmdError{kind: kind, err: err}
+}
+
+// validArgs reports arguments that a command doesn't accept as validation errors.
+func validArgs(check cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := check(cmd, args); err != nil {
+			return newError(errorValidation, err)
+		}
+		return nil
+	}
+}
+
+// errorKind works out what kind of error this is.
+func errorKind(err error) string {
+	if err == context.Canceled {
+		return errorInterrupted
+	}
+	switch e := err.(type) {
+	case *cmdError:
+		return e.kind
+	case *api.Error:
+		return apiErrorKind(e)
+	case workspace.ErrNotExist:
+		return errorNotFound
+	case workspace.ErrNotInWorkspace:
+		return errorValidation
+	case *url.Error:
+		if e.Err == context.Canceled {
+			return errorInterrupted
+		}
+		return errorNetwork
+	case net.Error:
+		return errorNetwork
+	case causer:
+		return errorKind(e.Cause())
+	}
+	return errorGeneric
+}
+
+func apiErrorKind(err *api.Error) string {
+	if err.Type == "track_ambiguous" {
+		return errorAmbiguousTrack
+	}
+	switch err.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errorUnauthorized
+	case http.StatusNotFound:
+		return errorNotFound
+	case http.StatusConflict:
+		return errorConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return errorValidation
+	}
+	if err.StatusCode >= 500 {
+		return errorNetwork
+	}
+	return errorGeneric
+}
+
+// exitCode is the exit code for an error.
+func exitCode(err error) int {
+	return exitCodes[errorKind(err)]
+}
+
+// apiError finds the API error behind an error, if there is one.
+func apiError(err error) (*api.Error, bool) {
+	if e, ok := err.(*cmdError); ok {
+		err = e.err
+	}
+	e, ok := err.(*api.Error)
+	return e, ok
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind string
+	}{
+		{errors.New("boom"), errorGeneric},
+		{newError(errorConflict, errors.New("boom")), errorConflict},
+		{&api.Error{StatusCode: 400, Type: "track_ambiguous"}, errorAmbiguousTrack},
+		{&api.Error{StatusCode: 401}, errorUnauthorized},
+		{&api.Error{StatusCode: 404}, errorNotFound},
+		{&api.Error{StatusCode: 409}, errorConflict},
+		{&api.Error{StatusCode: 422}, errorValidation},
+		{&api.Error{StatusCode: 503}, errorNetwork},
+		{&api.Error{StatusCode: 418}, errorGeneric},
+		{&url.Error{Op: "Get", URL: "http://example.com", Err: errors.New("connection refused")}, errorNetwork},
+		{&wrapped{&url.Error{Op: "Get", URL: "http://example.com", Err: errors.New("connection refused")}}, errorNetwork},
+		{workspace.ErrNotExist("bogus"), errorNotFound},
+		{workspace.ErrNotInWorkspace("bogus"), errorValidation},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.kind, errorKind(test.err), test.err.Error())
+	}
+}
+
+// wrapped adds context to an error, like the errors of the cli package.
+type wrapped struct {
+	err error
+}
+
+func (e *wrapped) Error() string {
+	return "error downloading: " + e.err.Error()
+}
+
+func (e *wrapped) Cause() error {
+	return e.err
+}
+
+func TestValidArgs(t *testing.T) {
+	check := validArgs(cobra.ExactArgs(1))
+	assert.NoError(t, check(&cobra.Command{}, []string{"one"}))
+
+	err := check(&cobra.Command{}, []string{"one", "two"})
+	assert.Equal(t, errorValidation, errorKind(err))
+	assert.Equal(t, 2, exitCode(err))
+}
+
+func TestExitCodes(t *testing.T) {
+	// Every kind has its own exit code.
+	seen := map[int]string{}
+	for kind, code := range exitCodes {
+		assert.NotEqual(t, 0, code, kind)
+		other, ok := seen[code]
+		assert.False(t, ok, "%s and %s share exit code %d", kind, other, code)
+		seen[code] = kind
+	}
+
+	assert.Equal(t, 6, exitCode(&api.Error{Type: "track_ambiguous"}))
+	assert.Equal(t, 1, exitCode(errors.New("boom")))
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// rootContext is cancelled when the command is interrupted, e.g. with Ctrl-C.
+// Commands pass it on to the API client, so that requests stop right away.
+// Commands that are called directly, like in tests, can't be interrupted.
+var rootContext = context.Background()
+
+var (
+	cleanupMu sync.Mutex
+	// cleanups undo unfinished work if a second interrupt exits right away.
+	cleanups []func()
+)
+
+// onForcedExit registers a function that cleans up, e.g. removes a temporary file,
+// if the command doesn't get a chance to finish.
+func onForcedExit(fn func()) {
+	cleanupMu.Lock()
+	defer cleanupMu.Unlock()
+	cleanups = append(cleanups, fn)
+}
+
+// forceExit cleans up what it can, and exits right away.
+func forceExit() {
+	cleanupMu.Lock()
+	for _, fn := range cleanups {
+		fn()
+	}
+	cleanupMu.Unlock()
+	stopTrace()
+	os.Exit(exitCodes[errorInterrupted])
+}
+
+// handleInterrupts cancels the root context on the first interrupt,
+// so that commands get a chance to clean up. A second interrupt cleans up
+// what it can, and exits right away.
+// The returned function stops listening.
+func handleInterrupts() func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	rootContext = ctx
+	cleanupMu.Lock()
+	cleanups = nil
+	cleanupMu.Unlock()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		fmt.Fprintln(Err, "\nInterrupted. Cleaning up, press Ctrl-C again to quit right away.")
+		cancel()
+
+		select {
+		case <-signals:
+			forceExit()
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
+
+// interrupted is true if the command was interrupted.
+func interrupted() bool {
+	return rootContext.Err() != nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/exercism/cli/config"
 	"github.com/exercism/cli/workspace"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // openCmd opens the designated exercise in the browser.
@@ -20,14 +21,16 @@ var openCmd = &cobra.Command{
 
 Pass either the name of an exercise, or the path to the directory that contains
 the solution you want to see on the website.
+
+Call the command with --print to print the URL instead of opening it.
 	`,
-	Args: cobra.ExactArgs(1),
+	Args: validArgs(cobra.ExactArgs(1)),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.NewUserConfig()
+		cfg, err := config.Load()
 		if err != nil {
 			return err
 		}
-		ws, err := workspace.New(cfg.Workspace)
+		ws, err := workspace.New(cfg.UserConfig.Workspace)
 		if err != nil {
 			return err
 		}
@@ -74,8 +77,7 @@ Type the number of the one you want to select.
 			}
 			solution, ok := option.(*workspace.Solution)
 			if ok {
-				browser.Open(solution.URL)
-				return nil
+				return openSolution(cmd.Flags(), solution)
 			}
 			if err != nil {
 				return errors.New("should never happen")
@@ -84,6 +86,34 @@ Type the number of the one you want to select.
 	},
 }
 
+// openDocument is the JSON output of the open command.
+type openDocument struct {
+	URL string `json:"url"`
+	Dir string `json:"dir"`
+}
+
+func openSolution(flags *pflag.FlagSet, solution *workspace.Solution) error {
+	print, err := flags.GetBool("print")
+	if err != nil {
+		return err
+	}
+	if !print {
+		browser.Open(solution.URL)
+	}
+	if wantsJSON(flags) {
+		return writeJSON(Out, openDocument{URL: solution.URL, Dir: solution.Dir})
+	}
+	if print {
+		fmt.Fprintln(Out, solution.URL)
+	}
+	return nil
+}
+
+func initOpenCmd() {
+	openCmd.Flags().BoolP("print", "p", false, "print the URL instead of opening it in the browser")
+}
+
 func init() {
 	RootCmd.AddCommand(openCmd)
+	initOpenCmd()
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Output formats for the --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// wantsJSON is true if the command should print a JSON document instead of text.
+// Commands that are called directly in tests may not have the flag.
+func wantsJSON(flags *pflag.FlagSet) bool {
+	f := flags.Lookup("output")
+	return f != nil && f.Value.String() == outputJSON
+}
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputText, outputJSON:
+		return nil
+	default:
+		return newError(errorValidation, fmt.Errorf("unknown output format '%s', use text or json", format))
+	}
+}
+
+// writeJSON prints a single JSON document.
+func writeJSON(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// errorDocument is how failures are reported in JSON.
+// Tools should branch on the type, not on the message.
+// The type is the kind of error, see errorKind.
+type errorDocument struct {
+	Error errorDetails `json:"error"`
+}
+
+type errorDetails struct {
+	Type     string   `json:"type"`
+	Message  string   `json:"message"`
+	ExitCode int      `json:"exit_code"`
+	TrackIDs []string `json:"track_ids,omitempty"`
+}
+
+func newErrorDocument(err error) errorDocument {
+	details := errorDetails{
+		Type:     errorKind(err),
+		Message:  strings.TrimSpace(err.Error()),
+		ExitCode: exitCode(err),
+	}
+	if apiErr, ok := apiError(err); ok {
+		details.TrackIDs = apiErr.PossibleTrackIDs
+	}
+	return errorDocument{Error: details}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/exercism/cli/api"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWantsJSON(t *testing.T) {
+	// Commands that are called directly may not have the flag.
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	assert.False(t, wantsJSON(flags))
+
+	flags.String("output", outputText, "")
+	assert.False(t, wantsJSON(flags))
+
+	assert.NoError(t, flags.Parse([]string{"--output", "json"}))
+	assert.True(t, wantsJSON(flags))
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	assert.NoError(t, validateOutputFormat("text"))
+	assert.NoError(t, validateOutputFormat("json"))
+	assert.Error(t, validateOutputFormat("yaml"))
+}
+
+func TestErrorDocument(t *testing.T) {
+	err := &api.Error{StatusCode: 400, Type: "track_ambiguous", Message: "pick one", PossibleTrackIDs: []string{"a", "b"}}
+	doc := newErrorDocument(newError(errorValidation, err))
+	assert.Equal(t, errorValidation, doc.Error.Type)
+	assert.Equal(t, 2, doc.Error.ExitCode)
+	assert.Equal(t, "pick one: a, b", doc.Error.Message)
+	assert.Equal(t, []string{"a", "b"}, doc.Error.TrackIDs)
+
+	doc = newErrorDocument(errors.New("\n\n    something broke\n"))
+	assert.Equal(t, errorGeneric, doc.Error.Type)
+	assert.Equal(t, "something broke", doc.Error.Message)
+}
@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
-	"net/http"
 
 	"github.com/exercism/cli/api"
 	"github.com/exercism/cli/config"
@@ -44,67 +41,34 @@ To customize the CLI to suit your own preferences, use the configure command.
 }
 
 func prepareTrack(id string) error {
-	cfg, err := config.NewUserConfig()
+	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
-	client, err := api.NewClient(cfg.Token, cfg.APIBaseURL)
+	client, err := api.NewClient(cfg.UserConfig.Token, cfg.UserConfig.APIBaseURL)
 	if err != nil {
 		return err
 	}
-	url := fmt.Sprintf("%s/tracks/%s", cfg.APIBaseURL, id)
-
-	req, err := client.NewRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-
-	res, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-
-	var payload prepareTrackPayload
-
-	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
-		return fmt.Errorf("unable to parse API response - %s", err)
-	}
-
-	if res.StatusCode != http.StatusOK {
-		return errors.New(payload.Error.Message)
-	}
-
-	cliCfg, err := config.NewCLIConfig()
+	client.Context = rootContext
+	track, err := client.GetTrack(id)
 	if err != nil {
 		return err
 	}
 
+	cliCfg := cfg.CLIConfig
 	t, ok := cliCfg.Tracks[id]
 	if !ok {
 		t = config.NewTrack(id)
 	}
-	if payload.Track.TestPattern != "" {
-		t.IgnorePatterns = append(t.IgnorePatterns, payload.Track.TestPattern)
+	if track.TestPattern != "" {
+		t.IgnorePatterns = append(t.IgnorePatterns, track.TestPattern)
 	}
 	cliCfg.Tracks[id] = t
 
 	return cliCfg.Write()
 }
 
-type prepareTrackPayload struct {
-	Track struct {
-		ID          string `json:"id"`
-		Language    string `json:"language"`
-		TestPattern string `json:"test_pattern"`
-	} `json:"track"`
-	Error struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
-}
-
 func initPrepareCmd() {
 	prepareCmd.Flags().StringP("track", "t", "", "the track you want to prepare")
 }
@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
 
 	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/cli"
 	"github.com/exercism/cli/config"
 	"github.com/exercism/cli/debug"
 	"github.com/spf13/cobra"
@@ -33,23 +35,142 @@ var RootCmd = &cobra.Command{
 	Short: "A friendly command-line interface to Exercism.",
 	Long: `A command-line interface for the v2 redesign of Exercism.
 
-Download exercises and submit your solutions.`,
+Download exercises and submit your solutions.
+
+When a command fails, the exit code tells you why:
+
+  1  something else went wrong
+  2  invalid arguments, flags, or settings
+  3  the API token was rejected
+  4  the CLI isn't configured yet, e.g. there is no token
+  5  the exercise, solution, or file could not be found
+  6  the exercise exists on more than one track, pass --track
+  7  the API could not be reached, or failed with a server error (5xx)
+  8  local files are in the way
+  130  the command was interrupted, e.g. with Ctrl-C
+
+With --output=json the error is printed as a JSON document,
+and its type names the reason.`,
 	SilenceUsage: true,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	// Execute reports errors, so that it can use the requested output format.
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if output, _ := cmd.Flags().GetString("output"); output != "" {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+		}
 		if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
 			debug.Verbose = verbose
 		}
+		if unredacted, _ := cmd.Flags().GetBool("verbose-unredacted"); unredacted {
+			debug.Verbose = true
+			debug.Unredacted = true
+		}
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			if err := config.SetProfile(profile); err != nil {
+				return newError(errorValidation, err)
+			}
+		} else if err := config.ValidateProfileEnv(); err != nil {
+			return newError(errorValidation, err)
+		}
+		if err := useCassette(); err != nil {
+			return err
+		}
+		if path, _ := cmd.Flags().GetString("trace"); path != "" {
+			if err := startTrace(path); err != nil {
+				return err
+			}
+		}
+		notifier = startUpdateCheck(cmd)
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		notifier.notify(Err)
 	},
 }
 
+// notifier tells people about new releases once their command has finished.
+var notifier *updateNotifier
+
+// cassette records or replays HTTP traffic when EXERCISM_CASSETTE is set.
+var cassette *api.Cassette
+
+// useCassette records or replays HTTP traffic when EXERCISM_CASSETTE is set.
+// It covers both the API client and the CLI's own HTTP client.
+// The cassette is only set up once, however often the commands run.
+func useCassette() error {
+	if cassette != nil {
+		return nil
+	}
+	c, err := api.CassetteFromEnv()
+	if err != nil || c == nil {
+		return err
+	}
+	api.DefaultHTTPClient.Transport = c.Transport(api.DefaultHTTPClient.Transport)
+	cli.HTTPClient.Transport = c.Transport(cli.HTTPClient.Transport)
+	cassette = c
+	return nil
+}
+
+// tracer records HTTP traffic when --trace is passed.
+var tracer *debug.Tracer
+
+// startTrace records the requests of both the API client and the CLI's own HTTP client.
+// The trace is only started once, however often the commands run.
+func startTrace(path string) error {
+	if tracer != nil {
+		return nil
+	}
+	t, err := debug.NewTracer(path)
+	if err != nil {
+		return err
+	}
+	t.Creator, t.Version = BinaryName, Version
+	api.DefaultHTTPClient.Transport = t.Transport(api.DefaultHTTPClient.Transport)
+	cli.HTTPClient.Transport = t.Transport(cli.HTTPClient.Transport)
+	tracer = t
+	return nil
+}
+
+// stopTrace finishes the trace file, if there is one.
+func stopTrace() {
+	if tracer == nil {
+		return
+	}
+	if err := tracer.Close(); err != nil {
+		fmt.Fprintf(Err, "Warning: unable to write the trace: %s\n", err)
+	}
+	tracer = nil
+}
+
 // Execute adds all child commands to the root command.
+// Failures exit with a code that depends on the type of error.
+// With --output=json the error is printed as a JSON document.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
-		os.Exit(-1)
+	stopInterrupts := handleInterrupts()
+	cmd, err := RootCmd.ExecuteC()
+	if err != nil && interrupted() && errorKind(err) != errorInterrupted {
+		err = newError(errorInterrupted, errors.New("the command was interrupted"))
+	}
+	stopInterrupts()
+	stopTrace()
+	if err == nil {
+		return
+	}
+	doc := newErrorDocument(err)
+	if cmd != nil && wantsJSON(cmd.Flags()) {
+		writeJSON(Out, doc)
+	} else {
+		fmt.Fprintf(Err, "Error: %s\n", err)
 	}
+	os.Exit(doc.Error.ExitCode)
 }
 
 func init() {
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return newError(errorValidation, err)
+	})
 	BinaryName = os.Args[0]
 	config.SetDefaultDirName(BinaryName)
 	Out = os.Stdout
@@ -57,4 +178,8 @@ func init() {
 	In = os.Stdin
 	api.UserAgent = fmt.Sprintf("github.com/exercism/cli v%s (%s/%s)", Version, runtime.GOOS, runtime.GOARCH)
 	RootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	RootCmd.PersistentFlags().BoolP("verbose-unredacted", "", false, "verbose output, including credentials (don't share it)")
+	RootCmd.PersistentFlags().StringP("profile", "", "", "the configuration profile to use")
+	RootCmd.PersistentFlags().StringP("output", "", outputText, "output format, text or json")
+	RootCmd.PersistentFlags().StringP("trace", "", "", "record HTTP requests in a file, as a HAR archive if it ends in .har or as JSON lines otherwise")
 }
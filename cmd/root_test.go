@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartTraceOnlyOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldAPITransport := api.DefaultHTTPClient.Transport
+	oldCLITransport := cli.HTTPClient.Transport
+	defer func() {
+		api.DefaultHTTPClient.Transport = oldAPITransport
+		cli.HTTPClient.Transport = oldCLITransport
+	}()
+
+	path := filepath.Join(dir, "trace.jsonl")
+	assert.NoError(t, startTrace(path))
+	defer stopTrace()
+	first := tracer
+	transport := api.DefaultHTTPClient.Transport
+
+	// Running the pre-run hook again doesn't start a new trace.
+	assert.NoError(t, startTrace(path))
+	assert.True(t, first == tracer)
+	assert.True(t, transport == api.DefaultHTTPClient.Transport)
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd lists the solutions in the person's workspace.
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"list"},
+	Short:   "List the solutions in your workspace.",
+	Long: `List the solutions in your Exercism workspace.
+
+Solutions are grouped by track. Your own solutions are listed
+separately from solutions by other people that you have downloaded.
+
+For each of your solutions the command shows whether it has been
+submitted from this computer, and which files have changed since
+the last submission.
+
+Call the command with --output=json to get the same information in a
+machine-readable format.
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		ws, err := workspace.New(cfg.UserConfig.Workspace)
+		if err != nil {
+			return err
+		}
+
+		statuses, err := newSolutionStatuses(ws, cfg.CLIConfig)
+		if err != nil {
+			return err
+		}
+
+		if wantsJSON(cmd.Flags()) {
+			return writeJSON(Out, statuses)
+		}
+		writeSolutionStatuses(Out, statuses)
+		return nil
+	},
+}
+
+// solutionStatus describes the local state of a solution.
+type solutionStatus struct {
+	Track        string     `json:"track"`
+	Exercise     string     `json:"exercise"`
+	ID           string     `json:"id"`
+	Handle       string     `json:"handle,omitempty"`
+	IsRequester  bool       `json:"is_requester"`
+	Dir          string     `json:"dir"`
+	Submitted    bool       `json:"submitted"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+	ChangedFiles []string   `json:"changed_files"`
+	solution     *workspace.Solution
+}
+
+func newSolutionStatuses(ws workspace.Workspace, cliCfg *config.CLIConfig) ([]*solutionStatus, error) {
+	solutions, err := ws.Solutions()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*solutionStatus, 0, len(solutions))
+	for _, solution := range solutions {
+		status := &solutionStatus{
+			Track:        solution.Track,
+			Exercise:     solution.Exercise,
+			ID:           solution.ID,
+			IsRequester:  solution.IsRequester,
+			Dir:          solution.Dir,
+			Submitted:    solution.SubmittedAt != nil,
+			SubmittedAt:  solution.SubmittedAt,
+			ChangedFiles: []string{},
+			solution:     solution,
+		}
+		if !solution.IsRequester {
+			status.Handle = solution.Handle
+		}
+		if status.Submitted {
+			track, ok := cliCfg.Tracks[solution.Track]
+			if !ok {
+				track = config.NewTrack(solution.Track)
+			}
+			status.ChangedFiles, err = changedFiles(solution, track)
+			if err != nil {
+				return nil, err
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Group by track, with the person's own solutions first.
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.Track != b.Track {
+			return a.Track < b.Track
+		}
+		if a.IsRequester != b.IsRequester {
+			return a.IsRequester
+		}
+		if a.Handle != b.Handle {
+			return a.Handle < b.Handle
+		}
+		return a.Dir < b.Dir
+	})
+	return statuses, nil
+}
+
+// changedFiles lists the files that were modified after the solution was last submitted.
+func changedFiles(solution *workspace.Solution, track *config.Track) ([]string, error) {
+	files, err := solution.Files(track.AcceptFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if !info.ModTime().After(*solution.SubmittedAt) {
+			continue
+		}
+		rel, err := filepath.Rel(solution.Dir, file)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, filepath.ToSlash(rel))
+	}
+	return changed, nil
+}
+
+func writeSolutionStatuses(w io.Writer, statuses []*solutionStatus) {
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "There are no solutions in your workspace yet.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	var track string
+	var mine bool
+	for _, status := range statuses {
+		if status.Track != track || status.IsRequester != mine {
+			track, mine = status.Track, status.IsRequester
+			heading := track
+			if !mine {
+				heading = fmt.Sprintf("%s (downloaded from other people)", track)
+			}
+			fmt.Fprintf(tw, "\n%s\n", heading)
+		}
+
+		var state string
+		switch {
+		case !status.IsRequester:
+			state = ""
+		case !status.Submitted:
+			state = "not submitted"
+		case len(status.ChangedFiles) == 0:
+			state = fmt.Sprintf("submitted %s", status.SubmittedAt.Local().Format("2006-01-02 15:04"))
+		default:
+			state = fmt.Sprintf("submitted %s, %d file(s) changed since", status.SubmittedAt.Local().Format("2006-01-02 15:04"), len(status.ChangedFiles))
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", status.solution, state, status.Dir)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/exercism/cli/workspace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	oldOut := Out
+	defer func() {
+		Out = oldOut
+	}()
+	var buf bytes.Buffer
+	Out = &buf
+
+	cmdTest := &CommandTest{
+		Cmd:    statusCmd,
+		InitFn: func() {},
+		Args:   []string{"fakeapp", "status", "--output=json"},
+	}
+	cmdTest.Setup(t)
+	defer cmdTest.Teardown(t)
+	cmdTest.App.PersistentFlags().String("output", outputText, "")
+
+	err := writeFakeUserConfigSettings(cmdTest.TmpDir, "http://example.com")
+	assert.NoError(t, err)
+
+	submittedAt := time.Now().Add(-time.Hour)
+	before := submittedAt.Add(-time.Minute)
+
+	solutions := map[string]*workspace.Solution{
+		filepath.Join("go", "leap"): {
+			ID:          "aaa",
+			Track:       "go",
+			Exercise:    "leap",
+			IsRequester: true,
+			SubmittedAt: &submittedAt,
+		},
+		filepath.Join("go", "clock"): {
+			ID:          "bbb",
+			Track:       "go",
+			Exercise:    "clock",
+			IsRequester: true,
+		},
+		filepath.Join("users", "alice", "go", "leap"): {
+			ID:       "ccc",
+			Track:    "go",
+			Exercise: "leap",
+			Handle:   "alice",
+		},
+	}
+	for dir, solution := range solutions {
+		path := filepath.Join(cmdTest.TmpDir, dir)
+		err := os.MkdirAll(path, os.FileMode(0755))
+		assert.NoError(t, err)
+		err = solution.Write(path)
+		assert.NoError(t, err)
+	}
+
+	dir := filepath.Join(cmdTest.TmpDir, "go", "leap")
+	unchanged := filepath.Join(dir, "leap.go")
+	err = ioutil.WriteFile(unchanged, []byte("package leap"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = os.Chtimes(unchanged, before, before)
+	assert.NoError(t, err)
+	changed := filepath.Join(dir, "helpers.go")
+	err = ioutil.WriteFile(changed, []byte("package leap"), os.FileMode(0644))
+	assert.NoError(t, err)
+	// Markdown files are ignored by default.
+	err = ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# Leap"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	err = cmdTest.App.Execute()
+	assert.NoError(t, err)
+
+	var statuses []solutionStatus
+	err = json.Unmarshal(buf.Bytes(), &statuses)
+	assert.NoError(t, err)
+
+	if assert.Equal(t, 3, len(statuses)) {
+		// Own solutions come first, sorted by directory.
+		assert.Equal(t, "bbb", statuses[0].ID)
+		assert.False(t, statuses[0].Submitted)
+		assert.Empty(t, statuses[0].ChangedFiles)
+
+		assert.Equal(t, "aaa", statuses[1].ID)
+		assert.True(t, statuses[1].Submitted)
+		assert.Equal(t, []string{"helpers.go"}, statuses[1].ChangedFiles)
+
+		assert.Equal(t, "ccc", statuses[2].ID)
+		assert.Equal(t, "alice", statuses[2].Handle)
+		assert.False(t, statuses[2].IsRequester)
+	}
+}
@@ -1,21 +1,21 @@
 package cmd
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/exercism/cli/api"
 	"github.com/exercism/cli/config"
 	"github.com/exercism/cli/workspace"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
 )
 
 // submitCmd lets people upload a solution to the website.
@@ -32,29 +32,25 @@ submit the exercise contained in the current directory.
 
 If called with the path to a directory, it will submit it.
 
+When submitting a directory, every file in the solution is
+sent, except hidden files and files matching the track's
+ignore patterns (by default Markdown files and the solution
+metadata). The list of files is printed before uploading.
+
 If called with the name of an exercise, it will work out which
 track it is on and submit it. The command will ask for help
 figuring things out if necessary.
+
+Call the command with --dry-run to see what would be submitted
+without sending anything. Add --output=json to get the same information
+in a machine-readable format.
 `,
-	Args: cobra.MaximumNArgs(1),
+	Args: validArgs(cobra.MaximumNArgs(1)),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg := config.NewConfiguration()
-
-		usrCfg := viper.New()
-		usrCfg.AddConfigPath(cfg.Dir)
-		usrCfg.SetConfigName("user")
-		usrCfg.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = usrCfg.ReadInConfig()
-		cfg.UserViperConfig = usrCfg
-
-		v := viper.New()
-		v.AddConfigPath(cfg.Dir)
-		v.SetConfigName("cli")
-		v.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = v.ReadInConfig()
-
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
 		return runSubmit(cfg, cmd.Flags(), args)
 	},
 }
@@ -62,7 +58,10 @@ figuring things out if necessary.
 func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) error {
 	usrCfg := cfg.UserViperConfig
 
-	if usrCfg.GetString("token") == "" {
+	dryRun, _ := flags.GetBool("dry-run")
+
+	// A dry run never talks to the API, so it doesn't need a token.
+	if usrCfg.GetString("token") == "" && !dryRun {
 		tokenURL := config.InferSiteURL(usrCfg.GetString("apibaseurl")) + "/my/settings"
 		msg := `
 
@@ -79,7 +78,7 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
         %s configure --token=YOUR_TOKEN
 
 		`
-		return fmt.Errorf(msg, tokenURL, BinaryName)
+		return newError(errorNotConfigured, fmt.Errorf(msg, tokenURL, BinaryName))
 	}
 
 	if usrCfg.GetString("workspace") == "" {
@@ -94,7 +93,11 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
 
         %s configure
 		`
-		return fmt.Errorf(msg, BinaryName)
+		return newError(errorNotConfigured, fmt.Errorf(msg, BinaryName))
+	}
+
+	if len(args) == 0 {
+		args = []string{"."}
 	}
 
 	for i, arg := range args {
@@ -108,19 +111,20 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
         %s
 
 		`
-				return fmt.Errorf(msg, arg)
+				return newError(errorNotFound, fmt.Errorf(msg, arg))
 			}
 			return err
 		}
-		if info.IsDir() {
+		if info.IsDir() && len(args) > 1 {
 			msg := `
 
-    You are submitting a directory, which is not currently supported.
+    You are submitting a directory together with other files.
+    Please submit either a single solution directory, or a list of files.
 
         %s
 
 			`
-			return fmt.Errorf(msg, arg)
+			return newError(errorValidation, fmt.Errorf(msg, arg))
 		}
 
 		src, err := filepath.EvalSymlinks(arg)
@@ -140,6 +144,26 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
 		return err
 	}
 
+	if len(tx.ArgDirs) > 0 {
+		// The directory may be anywhere within the solution,
+		// so find the root of the solution that it belongs to.
+		dir, err := filepath.Abs(tx.Dir)
+		if err != nil {
+			return err
+		}
+		tx.Dir, err = ws.SolutionDir(dir)
+		if err != nil {
+			msg := `
+
+    The directory you are submitting is not part of an exercise in your workspace.
+
+        %s
+
+			`
+			return newError(errorValidation, fmt.Errorf(msg, dir))
+		}
+	}
+
 	dirs, err := ws.Locate(tx.Dir)
 	if err != nil {
 		return err
@@ -157,7 +181,7 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
     Please see https://exercism.io/cli-v1-to-v2 for instructions on how to fix it.
 
 		`
-		return errors.New(msg)
+		return newError(errorValidation, errors.New(msg))
 	}
 	if len(sx) > 1 {
 		msg := `
@@ -166,7 +190,7 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
     Please submit the files for one solution at a time.
 
 		`
-		return errors.New(msg)
+		return newError(errorValidation, errors.New(msg))
 	}
 	solution := sx[0]
 
@@ -180,7 +204,29 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
         %s download --exercise=%s --track=%s
 
 		`
-		return fmt.Errorf(msg, BinaryName, solution.Exercise, solution.Track)
+		return newError(errorValidation, fmt.Errorf(msg, BinaryName, solution.Exercise, solution.Track))
+	}
+
+	if len(tx.ArgDirs) > 0 {
+		track := config.NewTrack(solution.Track)
+		if cfg.CLIConfig != nil {
+			if t, ok := cfg.CLIConfig.Tracks[solution.Track]; ok {
+				track = t
+			}
+		}
+		tx.Files, err = solution.Files(track.AcceptFilename)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(Err, "\n    Submitting files in %s\n\n", solution.Dir)
+		for _, file := range tx.Files {
+			rel, err := filepath.Rel(solution.Dir, file)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(Err, "        %s\n", filepath.ToSlash(rel))
+		}
 	}
 
 	paths := make([]string, 0, len(tx.Files))
@@ -210,59 +256,83 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
 		No files found to submit.
 
 		`
-		return errors.New(msg)
+		return newError(errorNotFound, errors.New(msg))
 	}
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	baseURL := usrCfg.GetString("apibaseurl")
+	if baseURL == "" {
+		baseURL = cfg.DefaultBaseURL
+	}
+	client, err := api.NewClient(usrCfg.GetString("token"), baseURL)
+	if err != nil {
+		return err
+	}
+	client.Context = rootContext
 
-	for _, path := range paths {
-		file, err := os.Open(path)
+	if dryRun {
+		manifest, err := newSubmitManifest(solution, client.SolutionURL(solution.ID), paths)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
-
-		dirname := fmt.Sprintf("%s%s%s", string(os.PathSeparator), solution.Exercise, string(os.PathSeparator))
-		pieces := strings.Split(path, dirname)
-		filename := fmt.Sprintf("%s%s", string(os.PathSeparator), pieces[len(pieces)-1])
-
-		part, err := writer.CreateFormFile("files[]", filename)
-		if err != nil {
-			return err
+		if wantsJSON(flags) {
+			return writeJSON(Out, manifest)
 		}
-		_, err = io.Copy(part, file)
+		manifest.write(Out)
+		return nil
+	}
+
+	files := make([]api.SolutionFile, 0, len(paths))
+	for _, path := range paths {
+		file, err := os.Open(path)
 		if err != nil {
 			return err
 		}
-	}
+		defer file.Close()
 
-	err = writer.Close()
-	if err != nil {
-		return err
+		files = append(files, api.SolutionFile{
+			Name:    submissionFilename(path, solution.Exercise),
+			Content: file,
+		})
 	}
 
-	client, err := api.NewClient(usrCfg.GetString("token"), usrCfg.GetString("apibaseurl"))
-	if err != nil {
-		return err
-	}
-	url := fmt.Sprintf("%s/solutions/%s", usrCfg.GetString("apibaseurl"), solution.ID)
-	req, err := client.NewRequest("PATCH", url, body)
-	if err != nil {
+	// Submitting the same files twice doesn't create a second iteration,
+	// so it is safe to try again.
+	client.Retry.RetryNonIdempotent = true
+	if err := client.UpdateSolution(solution.ID, files); err != nil {
+		if interrupted() {
+			// Nothing has been written locally, so there is nothing to undo.
+			msg := `
+
+    The submission was interrupted. It may or may not have reached the site.
+    Run the command again to make sure that your solution is submitted.
+			`
+			return newError(errorInterrupted, errors.New(msg))
+		}
 		return err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := client.Do(req)
-	if err != nil {
+	// Remember when this was submitted, so that we can tell
+	// which files have changed since.
+	now := time.Now()
+	solution.SubmittedAt = &now
+	if err := solution.Write(solution.Dir); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	bb := &bytes.Buffer{}
-	_, err = bb.ReadFrom(resp.Body)
-	if err != nil {
-		return err
+	if wantsJSON(flags) {
+		doc := submitDocument{
+			ID:          solution.ID,
+			Track:       solution.Track,
+			Exercise:    solution.Exercise,
+			URL:         solution.URL,
+			Dir:         solution.Dir,
+			SubmittedAt: now,
+			Files:       make([]string, 0, len(files)),
+		}
+		for _, file := range files {
+			doc.Files = append(doc.Files, file.Name)
+		}
+		return writeJSON(Out, doc)
 	}
 
 	msg := `
@@ -279,6 +349,84 @@ func runSubmit(cfg config.Configuration, flags *pflag.FlagSet, args []string) er
 	return nil
 }
 
+// submissionFilename is the name a file is uploaded under.
+// It is the path of the file relative to the exercise directory.
+func submissionFilename(path, exercise string) string {
+	dirname := fmt.Sprintf("%s%s%s", string(os.PathSeparator), exercise, string(os.PathSeparator))
+	pieces := strings.Split(path, dirname)
+	return fmt.Sprintf("%s%s", string(os.PathSeparator), pieces[len(pieces)-1])
+}
+
+// submitDocument is the JSON output of a submission.
+type submitDocument struct {
+	ID          string    `json:"id"`
+	Track       string    `json:"track"`
+	Exercise    string    `json:"exercise"`
+	URL         string    `json:"url"`
+	Dir         string    `json:"dir"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	Files       []string  `json:"files"`
+}
+
+// submitManifest describes what a submission would send to the API.
+type submitManifest struct {
+	Method     string               `json:"method"`
+	URL        string               `json:"url"`
+	SolutionID string               `json:"solution_id"`
+	Track      string               `json:"track"`
+	Exercise   string               `json:"exercise"`
+	Dir        string               `json:"dir"`
+	Files      []submitManifestFile `json:"files"`
+}
+
+// submitManifestFile describes a single file in a submission.
+type submitManifestFile struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Size is the number of bytes sent. Files are uploaded as they are, without transcoding.
+	Size int64 `json:"size"`
+	// SourceEncoding is the character encoding that the file appears to be in.
+	SourceEncoding string `json:"source_encoding"`
+}
+
+func newSubmitManifest(solution *workspace.Solution, url string, paths []string) (*submitManifest, error) {
+	manifest := &submitManifest{
+		Method:     "PATCH",
+		URL:        url,
+		SolutionID: solution.ID,
+		Track:      solution.Track,
+		Exercise:   solution.Exercise,
+		Dir:        solution.Dir,
+		Files:      make([]submitManifestFile, 0, len(paths)),
+	}
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, submitManifestFile{
+			Name:           submissionFilename(path, solution.Exercise),
+			Path:           path,
+			Size:           int64(len(b)),
+			SourceEncoding: api.DetectEncoding(b),
+		})
+	}
+	return manifest, nil
+}
+
+func (m *submitManifest) write(w io.Writer) {
+	fmt.Fprintf(w, "\nDry run. Nothing has been submitted.\n\n%s %s\n\n", m.Method, m.URL)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSIZE\tSOURCE ENCODING\tPATH")
+	for _, file := range m.Files {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", file.Name, file.Size, file.SourceEncoding, file.Path)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nFiles are sent as raw bytes, without converting them from their source encoding.")
+}
+
 func initSubmitCmd() {
 	setupSubmitFlags(submitCmd.Flags())
 }
@@ -287,8 +435,10 @@ func setupSubmitFlags(flags *pflag.FlagSet) {
 	flags.StringP("track", "t", "", "the track ID")
 	flags.StringP("exercise", "e", "", "the exercise ID")
 	flags.StringSliceP("files", "f", make([]string, 0), "files to submit")
+	flags.BoolP("dry-run", "n", false, "show what would be submitted, without submitting it")
 }
 
 func init() {
 	RootCmd.AddCommand(submitCmd)
+	initSubmitCmd()
 }
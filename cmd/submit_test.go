@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/exercism/cli/config"
@@ -149,11 +152,139 @@ func TestSubmitFiles(t *testing.T) {
 
 	assert.Equal(t, 3, len(submittedFiles))
 
+	// The submission is recorded in the solution metadata.
+	solution, err := workspace.NewSolution(dir)
+	assert.NoError(t, err)
+	assert.NotNil(t, solution.SubmittedAt)
+
 	assert.Equal(t, "This is file 1.", submittedFiles[string(os.PathSeparator)+"file-1.txt"])
 	assert.Equal(t, "This is file 2.", submittedFiles[string(os.PathSeparator)+filepath.Join("subdir", "file-2.txt")])
 	assert.Equal(t, "This is the readme.", submittedFiles[string(os.PathSeparator)+"README.md"])
 }
 
+func TestSubmitDirectory(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	// The fake endpoint will populate this when it receives the call from the command.
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-directory")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "subdir"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	files := map[string]string{
+		"file-1.txt":                           "This is file 1.",
+		filepath.Join("subdir", "file-2.txt"):  "This is file 2.",
+		filepath.Join("subdir", "file_test.x"): "This is a test.",
+		"README.md":                            "This is the readme.",
+	}
+	for name, contents := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), os.FileMode(0755))
+		assert.NoError(t, err)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cliCfg := config.NewEmptyCLIConfig()
+	cliCfg.Tracks["bogus-track"] = &config.Track{ID: "bogus-track", IgnorePatterns: []string{"_test[.]x$"}}
+	cliCfg.SetDefaults()
+
+	cfg := config.Configuration{
+		Persister:       config.InMemoryPersister{},
+		Dir:             tmpDir,
+		UserViperConfig: v,
+		CLIConfig:       cliCfg,
+	}
+
+	// Pass a directory inside the solution, rather than the solution itself.
+	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), []string{filepath.Join(dir, "subdir")})
+	assert.NoError(t, err)
+
+	// The readme and the test file are ignored.
+	var submitted []string
+	for _, contents := range submittedFiles {
+		submitted = append(submitted, contents)
+	}
+	sort.Strings(submitted)
+	assert.Equal(t, []string{"This is file 1.", "This is file 2."}, submitted)
+}
+
+func TestSubmitDryRun(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var buf bytes.Buffer
+	Out = &buf
+	Err = ioutil.Discard
+
+	tmpDir, err := ioutil.TempDir("", "submit-dry-run")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "subdir"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file1 := filepath.Join(dir, "file-1.txt")
+	err = ioutil.WriteFile(file1, []byte("This is file 1."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	file2 := filepath.Join(dir, "subdir", "file-2.txt")
+	err = ioutil.WriteFile(file2, []byte("This is file 2!!"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	// No token is needed, and the API is never called.
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", "http://example.com/api/v1")
+
+	cfg := config.Configuration{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.String("output", outputText, "")
+	err = flags.Parse([]string{"--dry-run", "--output=json"})
+	assert.NoError(t, err)
+
+	err = runSubmit(cfg, flags, []string{dir})
+	assert.NoError(t, err)
+
+	var manifest submitManifest
+	err = json.Unmarshal(buf.Bytes(), &manifest)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "PATCH", manifest.Method)
+	assert.Equal(t, "http://example.com/api/v1/solutions/bogus-solution-uuid", manifest.URL)
+	if assert.Equal(t, 2, len(manifest.Files)) {
+		assert.Equal(t, string(os.PathSeparator)+"file-1.txt", manifest.Files[0].Name)
+		assert.Equal(t, int64(15), manifest.Files[0].Size)
+		assert.Equal(t, "utf-8", manifest.Files[0].SourceEncoding)
+		assert.Equal(t, string(os.PathSeparator)+filepath.Join("subdir", "file-2.txt"), manifest.Files[1].Name)
+		assert.Equal(t, int64(16), manifest.Files[1].Size)
+	}
+}
+
 func TestSubmitWithEmptyFile(t *testing.T) {
 	oldOut := Out
 	oldErr := Err
@@ -6,12 +6,12 @@ import (
 	"html/template"
 	"net/http"
 	"runtime"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/exercism/cli/cli"
 	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/debug"
 	"github.com/spf13/cobra"
 )
 
@@ -29,68 +29,78 @@ If you're running into trouble, copy and paste the output from the troubleshoot
 command into a GitHub issue so we can help figure out what's going on.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cli.HTTPClient = &http.Client{Timeout: 20 * time.Second}
-		c := cli.New(Version)
-
-		cfg, err := config.NewUserConfig()
-		if err != nil {
+		cli.HTTPClient = &http.Client{Timeout: 20 * time.Second, Transport: cli.HTTPClient.Transport}
+		// A broken credential store is one of the things to troubleshoot.
+		cfg, err := config.Load()
+		credErr, ok := err.(*config.CredentialsError)
+		if err != nil && !ok {
 			return err
 		}
+		c := newCLI(cfg.CLIConfig)
 
-		status := newStatus(c, *cfg)
+		status := newStatus(c, *cfg.UserConfig)
 		status.Censor = !fullAPIKey
+		status.credErr = credErr
 		s, err := status.check()
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("%s", s)
+		if wantsJSON(cmd.Flags()) {
+			return writeJSON(Out, status)
+		}
+		fmt.Fprintf(Out, "%s", s)
 		return nil
 	},
 }
 
 // Status represents the results of a CLI self test.
+// It doubles as the JSON output of the troubleshoot command.
 type Status struct {
-	Censor          bool
-	Version         versionStatus
-	System          systemStatus
-	Configuration   configurationStatus
-	APIReachability apiReachabilityStatus
+	Censor          bool                  `json:"-"`
+	Version         versionStatus         `json:"version"`
+	System          systemStatus          `json:"system"`
+	Configuration   configurationStatus   `json:"configuration"`
+	APIReachability apiReachabilityStatus `json:"api_reachability"`
 	cli             *cli.CLI
 	cfg             config.UserConfig
+	credErr         *config.CredentialsError
 }
 
 type versionStatus struct {
-	Current  string
-	Latest   string
-	Status   string
-	Error    error
-	UpToDate bool
+	Current  string `json:"current"`
+	Latest   string `json:"latest"`
+	Status   string `json:"-"`
+	Error    string `json:"error,omitempty"`
+	UpToDate bool   `json:"up_to_date"`
 }
 
 type systemStatus struct {
-	OS           string
-	Architecture string
-	Build        string
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Build        string `json:"build,omitempty"`
 }
 
 type configurationStatus struct {
-	Home      string
-	Workspace string
-	File      string
-	Token     string
-	TokenURL  string
+	Profile   string `json:"profile"`
+	Home      string `json:"home"`
+	Workspace string `json:"workspace"`
+	File      string `json:"file"`
+	Token     string `json:"token"`
+	TokenURL  string `json:"token_url"`
+	// Credentials is the store that keeps the token.
+	Credentials string `json:"credentials"`
 }
 
 type apiReachabilityStatus struct {
-	Services []*apiPing
+	Services []*apiPing `json:"services"`
 }
 
 type apiPing struct {
-	Service string
-	URL     string
-	Status  string
-	Latency time.Duration
+	Service string        `json:"service"`
+	URL     string        `json:"url"`
+	Status  string        `json:"status"`
+	Latency time.Duration `json:"latency_ns"`
 }
 
 // newStatus prepares a value to perform a diagnostic self-check.
@@ -146,7 +156,7 @@ func newVersionStatus(c *cli.CLI) versionStatus {
 	if err == nil {
 		vs.Latest = c.LatestRelease.Version()
 	} else {
-		vs.Error = fmt.Errorf("Error: %s", err)
+		vs.Error = err.Error()
 	}
 	vs.UpToDate = ok
 	return vs
@@ -168,14 +178,24 @@ func newSystemStatus() systemStatus {
 
 func newConfigurationStatus(status *Status) configurationStatus {
 	cs := configurationStatus{
+		Profile:   config.Profile(),
 		Home:      status.cfg.Home,
 		Workspace: status.cfg.Workspace,
 		File:      status.cfg.File(),
 		Token:     status.cfg.Token,
 		TokenURL:  config.InferSiteURL(status.cfg.APIBaseURL) + "/my/settings",
 	}
+	store, err := config.NewCredentialStore(status.cfg.CredentialStore, status.cfg.APIBaseURL, config.Profile())
+	switch {
+	case err != nil:
+		cs.Credentials = err.Error()
+	case status.credErr != nil:
+		cs.Credentials = fmt.Sprintf("%s, error: %s", store.Name(), status.credErr.Err)
+	default:
+		cs.Credentials = store.Name()
+	}
 	if status.Censor && status.cfg.Token != "" {
-		cs.Token = redact(status.cfg.Token)
+		cs.Token = debug.Redact(status.cfg.Token)
 	}
 	return cs
 }
@@ -195,12 +215,6 @@ func (ping *apiPing) Call(wg *sync.WaitGroup) {
 	ping.Status = "connected"
 }
 
-func redact(token string) string {
-	str := token[4 : len(token)-3]
-	redaction := strings.Repeat("*", len(str))
-	return string(token[:4]) + redaction + string(token[len(token)-3:])
-}
-
 const tmplSelfTest = `
 Troubleshooting Information
 ===========================
@@ -210,7 +224,7 @@ Version
 Current: {{ .Version.Current }}
 Latest:  {{ with .Version.Latest }}{{ . }}{{ else }}<unknown>{{ end }}
 {{ with .Version.Error }}
-{{ . }}
+Error: {{ . }}
 {{ end -}}
 {{ if not .Version.UpToDate }}
 Call 'exercism upgrade' to get the latest version.
@@ -227,9 +241,11 @@ Build: {{ . }}
 
 Configuration
 ----------------
+Profile:   {{ .Configuration.Profile }}
 Home:      {{ .Configuration.Home }}
 Workspace: {{ .Configuration.Workspace }}
 Config:    {{ .Configuration.File }}
+Key store: {{ .Configuration.Credentials }}
 API key:   {{ with .Configuration.Token }}{{ . }}{{ else }}<not configured>
 Find your API key at {{ .Configuration.TokenURL }}{{ end }}
 
@@ -1,14 +1,45 @@
 package cmd
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/exercism/cli/config"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestRedact(t *testing.T) {
+func TestConfigurationStatusCensorsToken(t *testing.T) {
 	fakeToken := "1a11111aaaa111aa1a11111a11111aa1"
 	expected := "1a11*************************aa1"
 
-	assert.Equal(t, expected, redact(fakeToken))
+	uc := config.UserConfig{
+		Config:     config.New("/home/alice/.config/exercism", "user"),
+		Token:      fakeToken,
+		APIBaseURL: "http://example.com/api/v1",
+	}
+
+	status := newStatus(nil, uc)
+	status.Censor = true
+	assert.Equal(t, expected, newConfigurationStatus(&status).Token)
+
+	status.Censor = false
+	assert.Equal(t, fakeToken, newConfigurationStatus(&status).Token)
+
+	// There's nothing to censor without a token.
+	uc.Token = ""
+	status = newStatus(nil, uc)
+	status.Censor = true
+	assert.Equal(t, "", newConfigurationStatus(&status).Token)
+}
+
+func TestConfigurationStatusShowsCredentialsError(t *testing.T) {
+	uc := config.UserConfig{
+		Config:          config.New("/home/alice/.config/exercism", "user"),
+		APIBaseURL:      "http://example.com/api/v1",
+		CredentialStore: "keyring",
+	}
+
+	status := newStatus(nil, uc)
+	status.credErr = &config.CredentialsError{Err: errors.New("secret-tool not found")}
+	assert.Equal(t, "keyring (secret-tool), error: secret-tool not found", newConfigurationStatus(&status).Credentials)
 }
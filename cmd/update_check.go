@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/debug"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// updateCheckFilename caches the result of the last update check in the config dir.
+	updateCheckFilename = "update-check.json"
+	// updateCheckWait limits how long a command waits for
+	// the update check to finish before giving up on it.
+	updateCheckWait = 2 * time.Second
+	// noUpdateCheckKey disables the update check when set.
+	noUpdateCheckKey = "EXERCISM_NO_UPDATE_CHECK"
+)
+
+// isTerminal reports whether the writer is an interactive terminal.
+// It is overridden in tests.
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// updateCheck is the cached result of looking for a new release.
+type updateCheck struct {
+	CheckedAt     time.Time `json:"checked_at"`
+	LatestVersion string    `json:"latest_version"`
+}
+
+// updateNotifier looks for a new release while a command runs,
+// so that it can mention it once the command has finished.
+type updateNotifier struct {
+	done  chan struct{}
+	check *updateCheck
+}
+
+// startUpdateCheck kicks off the update check, if one is due.
+// It returns nil if no notice should be shown for this command.
+func startUpdateCheck(cmd *cobra.Command) *updateNotifier {
+	if !wantsUpdateNotice(cmd) {
+		return nil
+	}
+	cfg, err := loadWithoutCredentials()
+	if err != nil || cfg.CLIConfig.Upgrade.DisableCheck {
+		return nil
+	}
+	cliCfg := cfg.CLIConfig
+	interval, err := cliCfg.Upgrade.Interval()
+	if err != nil {
+		return nil
+	}
+
+	path := filepath.Join(config.Dir(), updateCheckFilename)
+	n := &updateNotifier{done: make(chan struct{})}
+
+	if check, err := readUpdateCheck(path); err == nil && time.Since(check.CheckedAt) < interval {
+		n.check = check
+		close(n.done)
+		return n
+	}
+
+	go func() {
+		defer close(n.done)
+
+		c := newCLI(cliCfg)
+		if _, err := c.IsUpToDate(); err != nil {
+			debug.Printf("Unable to check for a new version: %s\n", err)
+			return
+		}
+		check := &updateCheck{
+			CheckedAt:     time.Now(),
+			LatestVersion: c.LatestRelease.Version(),
+		}
+		if err := check.write(path); err != nil {
+			debug.Printf("Unable to cache the update check: %s\n", err)
+		}
+		n.check = check
+	}()
+	return n
+}
+
+// notify prints a notice if there is a newer version than this one.
+func (n *updateNotifier) notify(w io.Writer) {
+	if n == nil {
+		return
+	}
+	select {
+	case <-n.done:
+	case <-time.After(updateCheckWait):
+		return
+	}
+	if n.check == nil {
+		return
+	}
+
+	latest, err := semver.Make(n.check.LatestVersion)
+	if err != nil {
+		return
+	}
+	current, err := semver.Make(Version)
+	if err != nil || current.GTE(latest) {
+		return
+	}
+	fmt.Fprintf(w, "\nA new version of the CLI is available (%s). Run `%s upgrade` to update.\n", latest, BinaryName)
+}
+
+// wantsUpdateNotice decides whether a notice would be welcome.
+// It stays quiet when the output isn't going to a person,
+// and for the commands that already deal with versions.
+func wantsUpdateNotice(cmd *cobra.Command) bool {
+	if os.Getenv(noUpdateCheckKey) != "" {
+		return false
+	}
+	if !isTerminal(Err) {
+		return false
+	}
+	if asJSON, err := cmd.Flags().GetBool("json"); err == nil && asJSON {
+		return false
+	}
+	if wantsJSON(cmd.Flags()) {
+		return false
+	}
+	switch cmd.Name() {
+	case upgradeCmd.Name(), versionCmd.Name(), troubleshootCmd.Name():
+		return false
+	}
+	return true
+}
+
+func readUpdateCheck(path string) (*updateCheck, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var check updateCheck
+	if err := json.Unmarshal(b, &check); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+func (check *updateCheck) write(path string) error {
+	b, err := json.Marshal(check)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, os.FileMode(0644))
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/exercism/cli/cli"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateNotice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "update-check")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldConfigHome := os.Getenv(cfgHomeKey)
+	os.Setenv(cfgHomeKey, dir)
+	defer os.Setenv(cfgHomeKey, oldConfigHome)
+
+	oldNoCheck := os.Getenv(noUpdateCheckKey)
+	os.Unsetenv(noUpdateCheckKey)
+	defer os.Setenv(noUpdateCheckKey, oldNoCheck)
+
+	oldIsTerminal := isTerminal
+	terminal := true
+	isTerminal = func(io.Writer) bool { return terminal }
+	defer func() { isTerminal = oldIsTerminal }()
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprintln(w, `{"tag_name": "v99.0.0"}`)
+	}))
+	defer ts.Close()
+
+	oldReleaseURL := cli.ReleaseURL
+	cli.ReleaseURL = ts.URL
+	defer func() { cli.ReleaseURL = oldReleaseURL }()
+
+	cmd := &cobra.Command{Use: "fake"}
+	cmd.Flags().Bool("json", false, "")
+
+	notice := func() string {
+		var buf bytes.Buffer
+		startUpdateCheck(cmd).notify(&buf)
+		return buf.String()
+	}
+
+	// The first time, it asks for the latest release.
+	assert.Regexp(t, "new version.*99[.]0[.]0", notice())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	_, err = os.Lstat(filepath.Join(dir, updateCheckFilename))
+	assert.NoError(t, err)
+
+	// After that, it uses the cached result.
+	assert.Regexp(t, "new version.*99[.]0[.]0", notice())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Once the cache is stale, it checks again.
+	stale := &updateCheck{CheckedAt: time.Now().Add(-48 * time.Hour), LatestVersion: "1.0.0"}
+	err = stale.write(filepath.Join(dir, updateCheckFilename))
+	assert.NoError(t, err)
+	assert.Regexp(t, "new version.*99[.]0[.]0", notice())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// No notice if the latest version isn't newer.
+	current := &updateCheck{CheckedAt: time.Now(), LatestVersion: Version}
+	err = current.write(filepath.Join(dir, updateCheckFilename))
+	assert.NoError(t, err)
+	assert.Equal(t, "", notice())
+
+	// It stays quiet when it isn't talking to a person.
+	err = stale.write(filepath.Join(dir, updateCheckFilename))
+	assert.NoError(t, err)
+
+	terminal = false
+	assert.Equal(t, "", notice())
+	terminal = true
+
+	cmd.Flags().Set("json", "true")
+	assert.Equal(t, "", notice())
+	cmd.Flags().Set("json", "false")
+
+	os.Setenv(noUpdateCheckKey, "1")
+	assert.Equal(t, "", notice())
+	os.Unsetenv(noUpdateCheckKey)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
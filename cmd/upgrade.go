@@ -2,11 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/exercism/cli/cli"
+	"github.com/exercism/cli/config"
 	"github.com/spf13/cobra"
 )
 
+// releaseListLimit is the number of releases shown by upgrade --list.
+const releaseListLimit = 5
+
 // upgradeCmd downloads and installs the most recent version of the CLI.
 var upgradeCmd = &cobra.Command{
 	Use:     "upgrade",
@@ -17,16 +25,154 @@ var upgradeCmd = &cobra.Command{
 This finds and downloads the latest release, if you don't
 already have it.
 
+The download is checked against the checksums published with
+the release, and against its signature if the CLI was built
+with a public key. If anything doesn't match, nothing is
+installed.
+
+By default only stable releases are installed. Pass --channel=beta
+to include pre-releases, or --version to install a specific release,
+even if it is older than the one you have.
+
+To hold back upgrades, set a version constraint such as
+">=3.0.0 <4.0.0" in the Upgrade section of cli.json in the
+config directory. The channel can be set there too.
+
+Call the command with --list to see the recent releases
+along with their release notes.
+
+Releases are looked up on GitHub. To use a mirror that serves
+the same JSON, set the EXERCISM_RELEASE_URL environment variable,
+or ReleaseURL in the Upgrade section of cli.json.
+
+Without internet access, download the archive for your system
+some other way and install it with --from-file. Put the checksums
+file from the release, and the signature of the archive, in the
+same directory, so that the archive can be checked. To install an
+archive that can't be checked, e.g. one you built yourself, pass
+--insecure as well.
+
+Other commands look for a new release once a day, and mention
+it when they finish. Change how often with CheckInterval in the
+Upgrade section of cli.json. Turn it off with DisableCheck, or by
+setting the EXERCISM_NO_UPDATE_CHECK environment variable.
+
 On Windows the old CLI will be left on disk, marked as hidden.
 The next time you upgrade, the hidden file will be overwritten.
 You can always delete this file.
+
+A copy of the old CLI is also kept in the config directory,
+and every upgrade is recorded there. If a new release gets in
+your way, call the command with --rollback to go back to the
+version you had before.
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		c := cli.New(Version)
+		cfg, err := loadWithoutCredentials()
+		if err != nil {
+			return err
+		}
+		c := newCLI(cfg.CLIConfig)
+
+		rollback, err := cmd.Flags().GetBool("rollback")
+		if err != nil {
+			return err
+		}
+		if rollback {
+			if err := c.Rollback(); err != nil {
+				return err
+			}
+			fmt.Fprintln(Out, "The previous version of the CLI has been restored.")
+			return nil
+		}
+
+		file, err := cmd.Flags().GetString("from-file")
+		if err != nil {
+			return err
+		}
+		if file != "" {
+			c.Insecure, err = cmd.Flags().GetBool("insecure")
+			if err != nil {
+				return err
+			}
+			if err := c.UpgradeFromFile(file); err != nil {
+				return err
+			}
+			fmt.Fprintf(Out, "Installed the CLI from %s.\n", file)
+			return nil
+		}
+
+		channel, err := cmd.Flags().GetString("channel")
+		if err != nil {
+			return err
+		}
+		if channel != "" {
+			c.Channel = channel
+		}
+		settings := config.UpgradeSettings{Channel: c.Channel, Constraint: c.Constraint}
+		if err := settings.Validate(); err != nil {
+			return err
+		}
+
+		c.TargetVersion, err = cmd.Flags().GetString("version")
+		if err != nil {
+			return err
+		}
+
+		list, err := cmd.Flags().GetBool("list")
+		if err != nil {
+			return err
+		}
+		if list {
+			releases, err := c.Releases()
+			if err != nil {
+				return err
+			}
+			writeReleases(Out, releases)
+			return nil
+		}
+
 		return updateCLI(c)
 	},
 }
 
+// newCLI sets up the CLI with the upgrade settings from the config.
+func newCLI(cliCfg *config.CLIConfig) *cli.CLI {
+	c := cli.New(Version)
+	c.Channel = cliCfg.Upgrade.Channel
+	c.Constraint = cliCfg.Upgrade.Constraint
+	c.Backups = cli.NewBackups(filepath.Join(config.Dir(), "upgrades"))
+
+	if url := os.Getenv("EXERCISM_RELEASE_URL"); url != "" {
+		cli.ReleaseURL = strings.TrimRight(url, "/")
+	} else if cliCfg.Upgrade.ReleaseURL != "" {
+		cli.ReleaseURL = strings.TrimRight(cliCfg.Upgrade.ReleaseURL, "/")
+	}
+	return c
+}
+
+func writeReleases(w io.Writer, releases []cli.Release) {
+	if len(releases) == 0 {
+		fmt.Fprintln(w, "No releases found.")
+		return
+	}
+	if len(releases) > releaseListLimit {
+		releases = releases[:releaseListLimit]
+	}
+	for _, rel := range releases {
+		heading := rel.TagName
+		if rel.Prerelease {
+			heading += " (pre-release)"
+		}
+		if !rel.PublishedAt.IsZero() {
+			heading += fmt.Sprintf(", published %s", rel.PublishedAt.Format("2006-01-02"))
+		}
+		fmt.Fprintf(w, "\n%s\n", heading)
+		for _, line := range strings.Split(strings.TrimSpace(rel.Body), "\n") {
+			fmt.Fprintf(w, "    %s\n", strings.TrimRight(line, "\r"))
+		}
+	}
+}
+
 // updateCLI updates CLI to the latest available version, if it is out of date.
 func updateCLI(c cli.Updater) error {
 	ok, err := c.IsUpToDate()
@@ -42,6 +188,16 @@ func updateCLI(c cli.Updater) error {
 	return c.Upgrade()
 }
 
+func initUpgradeCmd() {
+	upgradeCmd.Flags().StringP("channel", "", "", "the release channel to upgrade from: stable or beta")
+	upgradeCmd.Flags().StringP("version", "", "", "install a specific version")
+	upgradeCmd.Flags().BoolP("list", "", false, "list recent releases with their release notes")
+	upgradeCmd.Flags().StringP("from-file", "", "", "install the CLI from a release archive on disk")
+	upgradeCmd.Flags().BoolP("insecure", "", false, "with --from-file, install the archive without checking it")
+	upgradeCmd.Flags().BoolP("rollback", "", false, "restore the version that was replaced by the last upgrade")
+}
+
 func init() {
 	RootCmd.AddCommand(upgradeCmd)
+	initUpgradeCmd()
 }
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"bytes"
 	"io/ioutil"
 	"testing"
+	"time"
 
+	"github.com/exercism/cli/cli"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,3 +56,28 @@ func TestUpgrade(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteReleases(t *testing.T) {
+	releases := []cli.Release{
+		{
+			TagName:     "v3.0.0-alpha.4",
+			Prerelease:  true,
+			PublishedAt: time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC),
+			Body:        "* Fix things\r\n* Break other things",
+		},
+		{TagName: "v2.4.1", Body: "Old news"},
+	}
+
+	var buf bytes.Buffer
+	writeReleases(&buf, releases)
+
+	expected := `
+v3.0.0-alpha.4 (pre-release), published 2018-07-01
+    * Fix things
+    * Break other things
+
+v2.4.1
+    Old news
+`
+	assert.Equal(t, expected, buf.String())
+}
@@ -26,22 +26,55 @@ To check for the latest available version, call the command with the
 	`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println(currentVersion())
+		if wantsJSON(cmd.Flags()) {
+			return writeVersionJSON()
+		}
+
+		fmt.Fprintln(Out, currentVersion())
 
 		if checkLatest {
-			c := cli.New(Version)
+			cfg, err := loadWithoutCredentials()
+			if err != nil {
+				return err
+			}
+			c := newCLI(cfg.CLIConfig)
 			l, err := checkForUpdate(c)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println(l)
+			fmt.Fprintln(Out, l)
 		}
 
 		return nil
 	},
 }
 
+// versionDocument is the JSON output of the version command.
+type versionDocument struct {
+	Version  string `json:"version"`
+	Latest   string `json:"latest,omitempty"`
+	UpToDate *bool  `json:"up_to_date,omitempty"`
+}
+
+func writeVersionJSON() error {
+	doc := versionDocument{Version: Version}
+	if checkLatest {
+		cfg, err := loadWithoutCredentials()
+		if err != nil {
+			return err
+		}
+		c := newCLI(cfg.CLIConfig)
+		ok, err := c.IsUpToDate()
+		if err != nil {
+			return err
+		}
+		doc.Latest = c.LatestRelease.Version()
+		doc.UpToDate = &ok
+	}
+	return writeJSON(Out, doc)
+}
+
 // currentVersion returns a formatted version string for the Exercism CLI.
 func currentVersion() string {
 	return fmt.Sprintf("exercism version %s", Version)
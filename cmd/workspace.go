@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -24,17 +25,59 @@ For example you can run:
 On Windows, this will work only with Powershell, however you would
 need to be on the same drive as your workspace directory. Otherwise
 nothing will happen.
+
+To find exercises by name quickly, the CLI keeps an index of your
+workspace. The index is kept up to date automatically. Call the command
+with --rebuild-index to rebuild it at any time.
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		usrCfg, err := config.NewUserConfig()
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		usrCfg := cfg.UserConfig
+
+		rebuild, err := cmd.Flags().GetBool("rebuild-index")
 		if err != nil {
 			return err
 		}
-		fmt.Println(usrCfg.Workspace)
+		doc := workspaceDocument{Workspace: usrCfg.Workspace}
+		if rebuild {
+			ws, err := workspace.New(usrCfg.Workspace)
+			if err != nil {
+				return err
+			}
+			idx, err := ws.RebuildIndex()
+			if err != nil {
+				return err
+			}
+			doc.Index = ws.IndexPath()
+			doc.IndexedSolutions = len(idx.Solutions)
+			if !wantsJSON(cmd.Flags()) {
+				fmt.Fprintf(Err, "Indexed %d solutions in %s\n", len(idx.Solutions), ws.IndexPath())
+			}
+		}
+
+		if wantsJSON(cmd.Flags()) {
+			return writeJSON(Out, doc)
+		}
+		fmt.Fprintln(Out, usrCfg.Workspace)
 		return nil
 	},
 }
 
+// workspaceDocument is the JSON output of the workspace command.
+type workspaceDocument struct {
+	Workspace        string `json:"workspace"`
+	Index            string `json:"index,omitempty"`
+	IndexedSolutions int    `json:"indexed_solutions,omitempty"`
+}
+
+func initWorkspaceCmd() {
+	workspaceCmd.Flags().BoolP("rebuild-index", "", false, "rebuild the index of the workspace")
+}
+
 func init() {
 	RootCmd.AddCommand(workspaceCmd)
+	initWorkspaceCmd()
 }
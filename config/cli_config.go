@@ -5,10 +5,14 @@ import "github.com/spf13/viper"
 // CLIConfig contains settings specific to the behavior of the CLI.
 type CLIConfig struct {
 	*Config
-	Tracks Tracks
+	// Version is the schema version of the file.
+	Version int
+	Tracks  Tracks
+	Upgrade UpgradeSettings
 }
 
 // NewCLIConfig loads the config file in the config directory.
+// A file with an older schema is upgraded the next time it is written.
 func NewCLIConfig() (*CLIConfig, error) {
 	cfg := NewEmptyCLIConfig()
 
@@ -16,7 +20,6 @@ func NewCLIConfig() (*CLIConfig, error) {
 		return nil, err
 	}
 	cfg.SetDefaults()
-
 	return cfg, nil
 }
 
@@ -34,6 +37,7 @@ func (cfg *CLIConfig) Write() error {
 	if err := cfg.Validate(); err != nil {
 		return err
 	}
+	cfg.Version = SchemaVersion
 	return Write(cfg)
 }
 
@@ -45,7 +49,7 @@ func (cfg *CLIConfig) Validate() error {
 			return err
 		}
 	}
-	return nil
+	return cfg.Upgrade.Validate()
 }
 
 // SetDefaults ensures that we have all the necessary settings for the CLI.
@@ -56,7 +60,12 @@ func (cfg *CLIConfig) SetDefaults() {
 }
 
 // Load reads a viper configuration into the config.
+// Older config files are upgraded to the current schema.
 func (cfg *CLIConfig) Load(v *viper.Viper) error {
 	cfg.readIn(v)
+	v, err := migrate(v, cliMigrations)
+	if err != nil {
+		return err
+	}
 	return v.Unmarshal(&cfg)
 }
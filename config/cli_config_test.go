@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"sort"
@@ -87,3 +88,21 @@ func TestCLIConfigSetDefaults(t *testing.T) {
 	sort.Strings(expected)
 	assert.Equal(t, expected, cfg.Tracks["bogus"].IgnorePatterns)
 }
+
+func TestUpgradeSettingsValidate(t *testing.T) {
+	testCases := []struct {
+		settings UpgradeSettings
+		valid    bool
+	}{
+		{UpgradeSettings{}, true},
+		{UpgradeSettings{Channel: "beta", Constraint: ">=3.0.0 <4.0.0"}, true},
+		{UpgradeSettings{Channel: "nightly"}, false},
+		{UpgradeSettings{Constraint: "three"}, false},
+	}
+
+	for _, tc := range testCases {
+		cfg := &CLIConfig{Upgrade: tc.settings}
+		err := cfg.Validate()
+		assert.Equal(t, tc.valid, err == nil, fmt.Sprintf("%+v", tc.settings))
+	}
+}
@@ -42,6 +42,7 @@ type filer interface {
 }
 
 // Write stores the config into a file.
+// Only the owner can read it, since it may contain the token.
 func Write(f filer) error {
 	b, err := json.Marshal(f)
 	if err != nil {
@@ -50,7 +51,10 @@ func Write(f filer) error {
 	if err := ensureDir(f); err != nil {
 		return err
 	}
-	return ioutil.WriteFile(f.File(), b, os.FileMode(0644))
+	if err := ioutil.WriteFile(f.File(), b, os.FileMode(0600)); err != nil {
+		return err
+	}
+	return os.Chmod(f.File(), os.FileMode(0600))
 }
 
 func ensureDir(f filer) error {
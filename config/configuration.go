@@ -15,14 +15,16 @@ var (
 )
 
 // Configuration lets us inject configuration options into commands.
-// Note that we are slowly working towards getting rid of the
-// config.Config, config.UserConfig, and config.CLIConfig types.
-// Once we do, we can rename this type to Config, and get rid of the
-// User and CLI fields.
+// Commands get it from Load, so they all see the same settings.
+// UserConfig and CLIConfig are the typed settings of user.json and
+// cli.json. UserViperConfig holds the raw user settings, for the
+// commands that change them and write them back with Save.
 type Configuration struct {
 	OS              string
 	Home            string
 	Dir             string
+	Profile         string
+	UserDir         string
 	DefaultBaseURL  string
 	DefaultDirName  string
 	UserViperConfig *viper.Viper
@@ -34,15 +36,17 @@ type Configuration struct {
 // NewConfiguration provides a configuration with default values.
 func NewConfiguration() Configuration {
 	home := userHome()
-	dir := Dir()
+	userDir := UserDir()
 
 	return Configuration{
 		OS:             runtime.GOOS,
 		Dir:            Dir(),
+		Profile:        Profile(),
+		UserDir:        userDir,
 		Home:           home,
 		DefaultBaseURL: defaultBaseURL,
 		DefaultDirName: DefaultDirName,
-		Persister:      FilePersister{Dir: dir},
+		Persister:      FilePersister{Dir: userDir},
 	}
 }
 
@@ -108,6 +112,46 @@ func DefaultWorkspaceDir(cfg Configuration) string {
 	return filepath.Join(cfg.Home, dir)
 }
 
+// Load reads the user config of the active profile and the CLI config.
+// Older config files are upgraded to the current schema in memory,
+// and are only written when the settings are saved. Environment
+// variables and the credential store are taken into account,
+// so that every command sees the same settings.
+// If the token can't be read from the credential store, the error is a
+// *CredentialsError, and the rest of the configuration is loaded anyway.
+func Load() (Configuration, error) {
+	cfg := NewConfiguration()
+
+	v, err := NewUserViperConfig(cfg.UserDir)
+	if err != nil {
+		return cfg, err
+	}
+	BindEnv(v)
+	// Commands that don't need the token can do without the credential store,
+	// so keep going and report the error with an otherwise complete configuration.
+	var credErr error
+	if err := LoadCredentials(v); err != nil {
+		credErr = &CredentialsError{Err: err}
+	}
+	cfg.UserViperConfig = v
+
+	usrCfg := NewEmptyUserConfig()
+	if err := v.Unmarshal(usrCfg); err != nil {
+		return cfg, err
+	}
+	cfg.UserConfig = usrCfg
+
+	cliCfg, err := NewCLIConfig()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.CLIConfig = cliCfg
+	return cfg, credErr
+}
+
+// Save writes the user config, using the current schema.
+// This is the only way the user config gets written.
 func (c Configuration) Save(basename string) error {
+	c.UserViperConfig.Set(schemaVersionKey, SchemaVersion)
 	return c.Persister.Save(c.UserViperConfig, basename)
 }
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// credentialStoreKey is the user config setting that picks a credential store.
+	credentialStoreKey = "credentialstore"
+	// helperPrefix marks a credential store setting that names a helper command.
+	helperPrefix = "helper:"
+	// keyringService identifies our secrets in the keyring.
+	keyringService = "exercism"
+)
+
+// secretTool talks to the Secret Service. It's a variable so tests can stand in for it.
+var secretTool = "secret-tool"
+
+// CredentialStore keeps the API token.
+type CredentialStore interface {
+	// Name describes the store, e.g. for troubleshooting.
+	Name() string
+	// Get returns the stored token, or an empty string if there isn't one.
+	Get() (string, error)
+	// Store saves the token.
+	Store(token string) error
+	// Erase forgets the token.
+	Erase() error
+}
+
+// NewCredentialStore picks a store based on the credentialstore setting.
+// The setting is one of:
+//
+//	file            the token is kept in user.json (the default)
+//	keyring         the token is kept in the Secret Service keyring
+//	helper:COMMAND  the token is managed by a git-style credential helper
+//
+// The API base URL and the profile tell tokens apart in shared stores.
+func NewCredentialStore(setting, baseURL, profile string) (CredentialStore, error) {
+	switch {
+	case setting == "" || setting == "file":
+		return FileCredentials{}, nil
+	case setting == "keyring":
+		return KeyringCredentials{Profile: profile}, nil
+	case strings.HasPrefix(setting, helperPrefix):
+		command := strings.TrimSpace(strings.TrimPrefix(setting, helperPrefix))
+		if command == "" {
+			return nil, fmt.Errorf("credential store '%s' does not name a helper command", setting)
+		}
+		return HelperCredentials{Command: command, BaseURL: baseURL, Profile: profile}, nil
+	default:
+		return nil, fmt.Errorf("unknown credential store '%s', use file, keyring, or helper:COMMAND", setting)
+	}
+}
+
+// credentialStoreFor finds the store configured in a user config.
+func credentialStoreFor(v *viper.Viper) (CredentialStore, error) {
+	baseURL := v.GetString("apibaseurl")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return NewCredentialStore(v.GetString(credentialStoreKey), baseURL, Profile())
+}
+
+// CredentialsError means that the token couldn't be read from the credential store.
+type CredentialsError struct {
+	Err error
+}
+
+func (e *CredentialsError) Error() string {
+	return fmt.Sprintf("unable to read the token from the credential store: %s", e.Err)
+}
+
+// LoadCredentials fills in the token from the configured credential store.
+// The token in the file or in the environment takes precedence.
+func LoadCredentials(v *viper.Viper) error {
+	store, err := credentialStoreFor(v)
+	if err != nil {
+		return err
+	}
+	if _, ok := store.(FileCredentials); ok {
+		return nil
+	}
+	token, err := store.Get()
+	if err != nil {
+		return err
+	}
+	v.SetDefault("token", token)
+	return nil
+}
+
+// saveCredentials moves the token out of the settings that are about to be written,
+// and into the configured credential store.
+// If the token was removed from the original config, it is erased from the store.
+func saveCredentials(original, settings *viper.Viper) (*viper.Viper, error) {
+	store, err := credentialStoreFor(settings)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := store.(FileCredentials); ok {
+		return settings, nil
+	}
+
+	token := settings.GetString("token")
+	switch {
+	case token != "":
+		err = store.Store(token)
+	case !original.IsSet("token"):
+		err = store.Erase()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clean := viper.New()
+	for key, value := range settings.AllSettings() {
+		if key != "token" {
+			clean.Set(key, value)
+		}
+	}
+	return clean, nil
+}
+
+// FileCredentials keeps the token in the user config file.
+// The file is only readable by its owner.
+type FileCredentials struct{}
+
+// Name describes the store.
+func (FileCredentials) Name() string {
+	return "file"
+}
+
+// Get does nothing. The token is read along with the rest of the config.
+func (FileCredentials) Get() (string, error) {
+	return "", nil
+}
+
+// Store does nothing. The token is written along with the rest of the config.
+func (FileCredentials) Store(string) error {
+	return nil
+}
+
+// Erase does nothing. The token is removed along with the rest of the config.
+func (FileCredentials) Erase() error {
+	return nil
+}
+
+// KeyringCredentials keeps the token in the Secret Service keyring, using secret-tool.
+type KeyringCredentials struct {
+	Profile string
+}
+
+// Name describes the store.
+func (s KeyringCredentials) Name() string {
+	return "keyring (secret-tool)"
+}
+
+// Get looks up the token.
+func (s KeyringCredentials) Get() (string, error) {
+	out, err := s.run("", "lookup")
+	if _, ok := err.(*exec.ExitError); ok {
+		// secret-tool fails when there is no matching secret.
+		return "", nil
+	}
+	return strings.TrimSpace(out), err
+}
+
+// Store saves the token.
+func (s KeyringCredentials) Store(token string) error {
+	label := fmt.Sprintf("--label=Exercism API token (%s)", s.Profile)
+	_, err := s.run(token, "store", label)
+	return err
+}
+
+// Erase removes the token.
+func (s KeyringCredentials) Erase() error {
+	_, err := s.run("", "clear")
+	return err
+}
+
+func (s KeyringCredentials) run(input, action string, args ...string) (string, error) {
+	args = append([]string{action}, args...)
+	args = append(args, "service", keyringService, "profile", s.Profile)
+	return runCredentialCommand(exec.Command(secretTool, args...), input)
+}
+
+// HelperCredentials hands the token to an external credential helper.
+// It speaks the same protocol as git credential helpers:
+// the helper is called with get, store, or erase, and reads key=value lines
+// on stdin, describing the credential. Get prints the password the same way.
+type HelperCredentials struct {
+	Command string
+	BaseURL string
+	Profile string
+}
+
+// Name describes the store.
+func (s HelperCredentials) Name() string {
+	return fmt.Sprintf("helper (%s)", s.Command)
+}
+
+// Get asks the helper for the token.
+func (s HelperCredentials) Get() (string, error) {
+	out, err := s.run("get", "")
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), "=", 2)
+		if len(kv) == 2 && kv[0] == "password" {
+			return kv[1], nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// Store gives the token to the helper.
+func (s HelperCredentials) Store(token string) error {
+	_, err := s.run("store", token)
+	return err
+}
+
+// Erase tells the helper to forget the token.
+func (s HelperCredentials) Erase() error {
+	_, err := s.run("erase", "")
+	return err
+}
+
+func (s HelperCredentials) run(action, token string) (string, error) {
+	var input bytes.Buffer
+	protocol, host := "https", s.BaseURL
+	if u, err := url.Parse(s.BaseURL); err == nil && u.Host != "" {
+		protocol, host = u.Scheme, u.Host
+	}
+	fmt.Fprintf(&input, "protocol=%s\nhost=%s\nusername=%s\n", protocol, host, s.Profile)
+	if token != "" {
+		fmt.Fprintf(&input, "password=%s\n", token)
+	}
+	input.WriteString("\n")
+
+	return runCredentialCommand(helperCommand(s.Command, action), input.String())
+}
+
+// helperCommand runs a helper with the action as its last argument.
+// The command is split on spaces, unless it names an existing file, and
+// quotes keep arguments with spaces together. On Windows, commands that
+// aren't executables, e.g. batch files on the path, are handed to cmd.
+func helperCommand(command, action string) *exec.Cmd {
+	args := splitCommand(command)
+	if _, err := os.Stat(command); err == nil {
+		args = []string{command}
+	}
+	args = append(args, action)
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			return exec.Command("cmd", append([]string{"/C"}, args...)...)
+		}
+	}
+	return exec.Command(args[0], args[1:]...)
+}
+
+// splitCommand splits a command line into arguments.
+// Single and double quotes group words. Backslashes are kept as they are,
+// since they separate directories on Windows.
+func splitCommand(command string) []string {
+	var args []string
+	var arg bytes.Buffer
+	var quote rune
+	inArg := false
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+func runCredentialCommand(cmd *exec.Cmd, input string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
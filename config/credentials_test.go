@@ -0,0 +1,193 @@
+//go:build !windows
+// +build !windows
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCredentialStore(t *testing.T) {
+	tests := []struct {
+		setting string
+		name    string
+		ok      bool
+	}{
+		{"", "file", true},
+		{"file", "file", true},
+		{"keyring", "keyring (secret-tool)", true},
+		{"helper:pass-exercism", "helper (pass-exercism)", true},
+		{"helper:", "", false},
+		{"vault", "", false},
+	}
+	for _, test := range tests {
+		store, err := NewCredentialStore(test.setting, defaultBaseURL, DefaultProfile)
+		if !test.ok {
+			assert.Error(t, err, test.setting)
+			continue
+		}
+		assert.NoError(t, err, test.setting)
+		assert.Equal(t, test.name, store.Name())
+	}
+}
+
+// fakeHelper writes a credential helper that keeps the password in a file.
+func fakeHelper(t *testing.T, dir string) string {
+	secret := filepath.Join(dir, "secret")
+	input := filepath.Join(dir, "input")
+	script := fmt.Sprintf(`#!/bin/sh
+cat > "%[2]s"
+case "$1" in
+get) if [ -f "%[1]s" ]; then echo "password=$(cat "%[1]s")"; fi ;;
+store) sed -n 's/^password=//p' "%[2]s" > "%[1]s" ;;
+erase) rm -f "%[1]s" ;;
+esac
+`, secret, input)
+	path := filepath.Join(dir, "helper")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(script), os.FileMode(0755)))
+	return path
+}
+
+func TestHelperCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-helper")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := HelperCredentials{Command: fakeHelper(t, dir), BaseURL: "http://example.com/api/v1", Profile: "work"}
+
+	token, err := store.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "", token)
+
+	assert.NoError(t, store.Store("abc123"))
+	b, err := ioutil.ReadFile(filepath.Join(dir, "input"))
+	assert.NoError(t, err)
+	assert.Equal(t, "protocol=http\nhost=example.com\nusername=work\npassword=abc123\n\n", string(b))
+
+	token, err = store.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", token)
+
+	assert.NoError(t, store.Erase())
+	token, err = store.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "", token)
+}
+
+func TestHelperCredentialsPathWithSpaces(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-helper")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	dir = filepath.Join(dir, "my helpers")
+	assert.NoError(t, os.Mkdir(dir, os.FileMode(0755)))
+	helper := fakeHelper(t, dir)
+
+	for _, command := range []string{helper, fmt.Sprintf(`"%s"`, helper)} {
+		store := HelperCredentials{Command: command, BaseURL: "http://example.com/api/v1", Profile: "work"}
+		assert.NoError(t, store.Store("abc123"))
+		token, err := store.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", token)
+		assert.NoError(t, store.Erase())
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+	}{
+		{"pass-exercism", []string{"pass-exercism"}},
+		{"  helper  --file  creds ", []string{"helper", "--file", "creds"}},
+		{`"/opt/my helpers/helper" --name 'the token'`, []string{"/opt/my helpers/helper", "--name", "the token"}},
+		{`C:\tools\helper.exe ""`, []string{`C:\tools\helper.exe`, ""}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.args, splitCommand(test.command), test.command)
+	}
+}
+
+func TestLoadWithBrokenCredentialStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldHome := os.Getenv("EXERCISM_CONFIG_HOME")
+	os.Setenv("EXERCISM_CONFIG_HOME", dir)
+	defer os.Setenv("EXERCISM_CONFIG_HOME", oldHome)
+
+	// The helper is gone.
+	settings := `{"workspace":"/a","credentialstore":"helper:` + filepath.Join(dir, "missing") + `"}`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "user.json"), []byte(settings), os.FileMode(0600)))
+
+	cfg, err := Load()
+	_, ok := err.(*CredentialsError)
+	assert.True(t, ok, "%v", err)
+	assert.Equal(t, "/a", cfg.UserConfig.Workspace)
+}
+
+func TestFilePersisterWithCredentialHelper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-persister")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	helper := fakeHelper(t, dir)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", "/a")
+	v.Set("credentialstore", "helper:"+helper)
+	p := FilePersister{Dir: dir}
+	assert.NoError(t, p.Save(v, "user"))
+
+	// The token is not in the file, and the file is private.
+	path := filepath.Join(dir, "user.json")
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "abc123")
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// It comes back when loading the config.
+	cfg := &UserConfig{Config: New(dir, "user")}
+	assert.NoError(t, cfg.Load(viper.New()))
+	assert.Equal(t, "abc123", cfg.Token)
+	assert.Equal(t, "/a", cfg.Workspace)
+
+	// Removing the token erases it from the store.
+	v = viper.New()
+	v.Set("workspace", "/a")
+	v.Set("credentialstore", "helper:"+helper)
+	assert.NoError(t, p.Save(v, "user"))
+	_, err = os.Stat(filepath.Join(dir, "secret"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFilePersisterIsPrivate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-persister")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// An existing file gets locked down too.
+	path := filepath.Join(dir, "user.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{}"), os.FileMode(0644)))
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	assert.NoError(t, FilePersister{Dir: dir}.Save(v, "user"))
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "abc123")
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
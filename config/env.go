@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// envOverrides maps user config keys to the environment variables that override them.
+// Overrides only last for the current command. They are never written to disk.
+var envOverrides = map[string]string{
+	"token":      "EXERCISM_TOKEN",
+	"workspace":  "EXERCISM_WORKSPACE",
+	"apibaseurl": "EXERCISM_API_BASE_URL",
+}
+
+// boundEnv keeps track of the user configs that environment variables are bound to,
+// and of the keys in each that were set explicitly since, e.g. from a flag.
+var (
+	boundEnvMu sync.Mutex
+	boundEnv   = map[*viper.Viper]map[string]bool{}
+)
+
+// BindEnv lets environment variables override the settings in a user config.
+func BindEnv(v *viper.Viper) {
+	for key, env := range envOverrides {
+		v.BindEnv(key, env)
+	}
+	boundEnvMu.Lock()
+	defer boundEnvMu.Unlock()
+	if _, ok := boundEnv[v]; !ok {
+		boundEnv[v] = map[string]bool{}
+	}
+}
+
+// SetExplicitly changes a setting on purpose, e.g. because it was passed as a flag.
+// Unlike a value that was taken from the environment, it is saved,
+// even if it happens to be the same as the environment variable.
+func SetExplicitly(v *viper.Viper, key string, value interface{}) {
+	v.Set(key, value)
+	boundEnvMu.Lock()
+	defer boundEnvMu.Unlock()
+	if explicit, ok := boundEnv[v]; ok {
+		explicit[key] = true
+	}
+}
+
+// fromEnv decides whether the value of a key comes from the environment.
+func fromEnv(v *viper.Viper, key string) bool {
+	if _, override := EnvOverride(key); override == "" {
+		return false
+	}
+	boundEnvMu.Lock()
+	defer boundEnvMu.Unlock()
+	explicit, ok := boundEnv[v]
+	return ok && !explicit[key]
+}
+
+// EnvOverride names the environment variable that overrides a user config key,
+// and returns its value. The value is empty if the variable isn't set.
+func EnvOverride(key string) (string, string) {
+	env, ok := envOverrides[key]
+	if !ok {
+		return "", ""
+	}
+	return env, os.Getenv(env)
+}
+
+// persistableSettings leaves out the values that come from the environment.
+// If a key is overridden, whatever is already on disk is kept instead.
+// Settings that weren't bound to the environment are kept as they are.
+func persistableSettings(v *viper.Viper, path string) *viper.Viper {
+	onDisk := viper.New()
+	onDisk.SetConfigFile(path)
+	onDisk.SetConfigType("json")
+	// Ignore error. If the file doesn't exist, that is fine.
+	_ = onDisk.ReadInConfig()
+
+	clean := viper.New()
+	for key, value := range v.AllSettings() {
+		if fromEnv(v, key) {
+			if onDisk.IsSet(key) {
+				clean.Set(key, onDisk.Get(key))
+			}
+			continue
+		}
+		clean.Set(key, value)
+	}
+	return clean
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvOverridesAreNotPersisted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env-overrides")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldToken := os.Getenv("EXERCISM_TOKEN")
+	defer os.Setenv("EXERCISM_TOKEN", oldToken)
+	os.Setenv("EXERCISM_TOKEN", "from-env")
+
+	v := viper.New()
+	v.Set("token", "from-file")
+	v.Set("workspace", "/a")
+	p := FilePersister{Dir: dir}
+	assert.NoError(t, p.Save(v, "user"))
+
+	// The environment wins when reading.
+	cfg := &UserConfig{Config: New(dir, "user")}
+	assert.NoError(t, cfg.Load(viper.New()))
+	assert.Equal(t, "from-env", cfg.Token)
+	assert.Equal(t, "/a", cfg.Workspace)
+
+	// Saving again keeps the value that was on disk.
+	v = viper.New()
+	v.AddConfigPath(dir)
+	v.SetConfigName("user")
+	assert.NoError(t, v.ReadInConfig())
+	BindEnv(v)
+	v.Set("workspace", "/b")
+	assert.NoError(t, p.Save(v, "user"))
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "user.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "from-file")
+	assert.NotContains(t, string(b), "from-env")
+	assert.Contains(t, string(b), "/b")
+
+	// An override that isn't on disk yet is left out entirely.
+	assert.NoError(t, os.Remove(filepath.Join(dir, "user.json")))
+	assert.NoError(t, p.Save(v, "user"))
+	b, err = ioutil.ReadFile(filepath.Join(dir, "user.json"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "token")
+}
+
+func TestExplicitSettingsArePersisted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env-overrides")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldToken := os.Getenv("EXERCISM_TOKEN")
+	defer os.Setenv("EXERCISM_TOKEN", oldToken)
+	os.Setenv("EXERCISM_TOKEN", "abc123")
+
+	v := viper.New()
+	BindEnv(v)
+	// The token passed as a flag happens to be the same as the environment variable.
+	SetExplicitly(v, "token", "abc123")
+	p := FilePersister{Dir: dir}
+	assert.NoError(t, p.Save(v, "user"))
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "user.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "abc123")
+}
@@ -19,11 +19,22 @@ type FilePersister struct {
 }
 
 // Save writes the viper config to the target location on the filesystem.
+// Settings that are overridden by environment variables are not written,
+// and the token goes to the configured credential store.
 func (p FilePersister) Save(v *viper.Viper, basename string) error {
-	v.SetConfigType("json")
-	v.AddConfigPath(p.Dir)
-	v.SetConfigName(basename)
+	clean, err := saveCredentials(v, persistableSettings(v, p.path(basename)))
+	if err != nil {
+		return err
+	}
+	return p.write(clean, basename)
+}
+
+func (p FilePersister) path(basename string) string {
+	return filepath.Join(p.Dir, fmt.Sprintf("%s.json", basename))
+}
 
+// write stores the settings as they are.
+func (p FilePersister) write(v *viper.Viper, basename string) error {
 	if _, err := os.Stat(p.Dir); os.IsNotExist(err) {
 		if err := os.MkdirAll(p.Dir, os.FileMode(0755)); err != nil {
 			return err
@@ -35,10 +46,29 @@ func (p FilePersister) Save(v *viper.Viper, basename string) error {
 	// but the fix doesn't work yet.
 	// When it's fixed and merged we can get rid of `path`
 	// and use viperConfig.WriteConfig() directly.
-	path := filepath.Join(p.Dir, fmt.Sprintf("%s.json", basename))
+	path := p.path(basename)
+	// The config may contain the token, so keep it private.
+	// Viper doesn't change the permissions of an existing file.
+	if err := ensurePrivate(path); err != nil {
+		return err
+	}
+	v.SetConfigType("json")
+	v.AddConfigPath(p.Dir)
+	v.SetConfigName(basename)
 	return v.WriteConfigAs(path)
 }
 
+func ensurePrivate(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, os.FileMode(0600))
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(path, os.FileMode(0600))
+}
+
 // InMemoryPersister is a noop persister for use in unit tests.
 type InMemoryPersister struct{}
 
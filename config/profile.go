@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const (
+	// DefaultProfile keeps its settings directly in the config dir.
+	DefaultProfile = "default"
+	// profileEnvKey selects the profile if there is no --profile flag.
+	profileEnvKey = "EXERCISM_PROFILE"
+	// profilesDirname is the directory in the config dir
+	// that holds the settings of the other profiles.
+	profilesDirname = "profiles"
+)
+
+var (
+	// profile is the profile chosen with the --profile flag.
+	profile string
+
+	profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// SetProfile selects the profile to use, overriding the environment.
+func SetProfile(name string) error {
+	if err := ValidateProfile(name); err != nil {
+		return err
+	}
+	profile = name
+	return nil
+}
+
+// ValidateProfile makes sure the name can be used as a directory name.
+func ValidateProfile(name string) error {
+	if !profileNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s', use only letters, numbers, dashes and underscores", name)
+	}
+	return nil
+}
+
+// ValidateProfileEnv makes sure the profile chosen in the environment, if any,
+// can be used. Otherwise Profile would quietly fall back to the default profile.
+func ValidateProfileEnv() error {
+	if name := os.Getenv(profileEnvKey); name != "" {
+		return ValidateProfile(name)
+	}
+	return nil
+}
+
+// Profile is the name of the active profile.
+// Each profile has its own token, workspace, and API base URL,
+// which makes it possible to switch between accounts and sites.
+func Profile() string {
+	if profile != "" {
+		return profile
+	}
+	if name := os.Getenv(profileEnvKey); name != "" && ValidateProfile(name) == nil {
+		return name
+	}
+	return DefaultProfile
+}
+
+// UserDir is the directory that holds the user config of the active profile.
+func UserDir() string {
+	name := Profile()
+	if name == DefaultProfile {
+		return Dir()
+	}
+	return filepath.Join(Dir(), profilesDirname, name)
+}
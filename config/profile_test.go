@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	oldProfileEnv := os.Getenv(profileEnvKey)
+	oldProfile := profile
+	defer func() {
+		os.Setenv(profileEnvKey, oldProfileEnv)
+		profile = oldProfile
+	}()
+
+	os.Unsetenv(profileEnvKey)
+	profile = ""
+
+	// The default profile lives in the config dir.
+	assert.Equal(t, DefaultProfile, Profile())
+	assert.Equal(t, Dir(), UserDir())
+
+	// The environment picks a profile.
+	os.Setenv(profileEnvKey, "staging")
+	assert.Equal(t, "staging", Profile())
+	assert.Equal(t, filepath.Join(Dir(), "profiles", "staging"), UserDir())
+	assert.Equal(t, filepath.Join(Dir(), "profiles", "staging", "user.json"), NewEmptyUserConfig().File())
+
+	assert.NoError(t, ValidateProfileEnv())
+
+	// The flag beats the environment.
+	err := SetProfile("local")
+	assert.NoError(t, err)
+	assert.Equal(t, "local", Profile())
+	assert.Equal(t, filepath.Join(Dir(), "profiles", "local"), NewConfiguration().UserDir)
+
+	// Profile names can't escape the config dir.
+	err = SetProfile("../elsewhere")
+	assert.Error(t, err)
+	assert.Equal(t, "local", Profile())
+
+	// Neither can the ones in the environment.
+	os.Setenv(profileEnvKey, "../elsewhere")
+	assert.Error(t, ValidateProfileEnv())
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// SchemaVersion is the layout of the config files written by this version of the CLI.
+// Files without a version predate versioning, and are at version 0.
+const SchemaVersion = 1
+
+// schemaVersionKey is the setting that records the schema version of a config file.
+const schemaVersionKey = "version"
+
+// migration upgrades settings from one schema version to the next.
+// The keys are lowercase, the way viper provides them.
+type migration func(settings map[string]interface{})
+
+// userMigrations upgrade the user config. The migration at index i starts from version i.
+var userMigrations = []migration{
+	migrateUserConfigV0,
+}
+
+// cliMigrations upgrade the CLI config. The migration at index i starts from version i.
+var cliMigrations = []migration{
+	migrateCLIConfigV0,
+}
+
+// migrateUserConfigV0 doesn't change anything. Files from before versioning
+// have the same settings, they just don't record the version, which migrate adds.
+// It holds the place of version 0, so that the next migration starts from version 1.
+func migrateUserConfigV0(settings map[string]interface{}) {}
+
+// migrateCLIConfigV0 fills in track IDs, which older files left out.
+func migrateCLIConfigV0(settings map[string]interface{}) {
+	tracks, ok := settings["tracks"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for id, track := range tracks {
+		t, ok := track.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if s, _ := t["id"].(string); s == "" {
+			t["id"] = id
+		}
+	}
+}
+
+// migrate upgrades the settings in a viper config to the current schema.
+// It returns the viper config that holds the upgraded settings,
+// which is v itself if there was nothing to upgrade.
+func migrate(v *viper.Viper, migrations []migration) (*viper.Viper, error) {
+	if len(v.AllKeys()) == 0 {
+		// There's nothing to upgrade.
+		return v, nil
+	}
+
+	version := v.GetInt(schemaVersionKey)
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("the config was written by a newer version of the CLI (schema version %d), call 'exercism upgrade'", version)
+	}
+	if version == SchemaVersion {
+		return v, nil
+	}
+
+	settings := v.AllSettings()
+	for _, m := range migrations[version:] {
+		m(settings)
+	}
+	settings[schemaVersionKey] = SchemaVersion
+
+	// Viper can't forget a key, so start over.
+	// Read the settings in as a config file, so that anything
+	// set later, like environment variables, still takes precedence.
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	migrated := viper.New()
+	migrated.SetConfigType("json")
+	if err := migrated.ReadConfig(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return migrated, nil
+}
+
+// NewUserViperConfig reads the user config in the directory.
+// If it was written with an older schema it is upgraded in memory.
+// The file itself is upgraded the next time it is saved.
+// Environment variables and credential stores are not taken into account.
+func NewUserViperConfig(dir string) (*viper.Viper, error) {
+	v := viper.New()
+	New(dir, "user").readIn(v)
+	return migrateUserConfig(v)
+}
+
+func migrateUserConfig(v *viper.Viper) (*viper.Viper, error) {
+	return migrate(v, userMigrations)
+}
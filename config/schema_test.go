@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateUserConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "user-config-schema")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The layout written by UserConfig before versioning.
+	path := filepath.Join(dir, "user.json")
+	legacy := `{"Workspace":"/a","Token":"abc123","Home":"/home/a","APIBaseURL":"http://example.com"}`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(legacy), os.FileMode(0600)))
+
+	cfg := &UserConfig{Config: New(dir, "user")}
+	assert.NoError(t, cfg.Load(viper.New()))
+	assert.Equal(t, "abc123", cfg.Token)
+	assert.Equal(t, "/a", cfg.Workspace)
+	assert.Equal(t, "http://example.com", cfg.APIBaseURL)
+
+	// Loading it doesn't touch the file.
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, legacy, string(b))
+
+	// Saving it records the schema version.
+	v, err := NewUserViperConfig(dir)
+	assert.NoError(t, err)
+	configuration := Configuration{UserViperConfig: v, Persister: FilePersister{Dir: dir}}
+	assert.NoError(t, configuration.Save("user"))
+
+	b, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	var settings map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &settings))
+	assert.Equal(t, map[string]interface{}{
+		"version":    float64(SchemaVersion),
+		"token":      "abc123",
+		"workspace":  "/a",
+		"home":       "/home/a",
+		"apibaseurl": "http://example.com",
+	}, settings)
+}
+
+func TestMigrateUserConfigFromTheFuture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "user-config-schema")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "user.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"version": 99, "token": "abc123"}`), os.FileMode(0600)))
+
+	_, err = NewUserViperConfig(dir)
+	assert.Error(t, err)
+}
+
+func TestMigrateCLIConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-config-schema")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cli.json")
+	legacy := `{"Tracks": {"go": {"IgnorePatterns": ["_test[.]go$"]}}}`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(legacy), os.FileMode(0644)))
+
+	cfg := &CLIConfig{Config: New(dir, "cli")}
+	assert.NoError(t, cfg.Load(viper.New()))
+	assert.Equal(t, "go", cfg.Tracks["go"].ID)
+
+	// Loading it doesn't touch the file.
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, legacy, string(b))
+
+	// Writing it records the schema version.
+	assert.NoError(t, cfg.Write())
+	v := viper.New()
+	New(dir, "cli").readIn(v)
+	assert.Equal(t, SchemaVersion, v.GetInt(schemaVersionKey))
+
+	cfg = &CLIConfig{Config: New(dir, "cli")}
+	assert.NoError(t, cfg.Load(viper.New()))
+	assert.Equal(t, SchemaVersion, cfg.Version)
+	assert.Equal(t, "go", cfg.Tracks["go"].ID)
+}
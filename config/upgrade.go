@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// UpgradeSettings control which releases the upgrade command installs.
+type UpgradeSettings struct {
+	// Channel is either stable or beta. The beta channel includes pre-releases.
+	// If it is empty, the stable channel is used.
+	Channel string
+	// Constraint is a semver range that releases must satisfy,
+	// e.g. ">=3.0.0 <4.0.0".
+	Constraint string
+	// ReleaseURL points at a mirror of the release feed.
+	// The EXERCISM_RELEASE_URL environment variable takes precedence.
+	ReleaseURL string
+	// CheckInterval is how often to look for a new release, e.g. "12h".
+	// If it is empty, we check once a day.
+	CheckInterval string
+	// DisableCheck stops the CLI from looking for new releases by itself.
+	DisableCheck bool
+}
+
+// defaultCheckInterval is how often we look for a new release by default.
+const defaultCheckInterval = 24 * time.Hour
+
+// Interval is how often to look for a new release.
+func (s UpgradeSettings) Interval() (time.Duration, error) {
+	if s.CheckInterval == "" {
+		return defaultCheckInterval, nil
+	}
+	return time.ParseDuration(s.CheckInterval)
+}
+
+// Validate ensures that the channel and constraint make sense.
+func (s UpgradeSettings) Validate() error {
+	switch s.Channel {
+	case "", "stable", "beta":
+	default:
+		return fmt.Errorf("unknown release channel '%s', use stable or beta", s.Channel)
+	}
+	if s.Constraint != "" {
+		if _, err := semver.ParseRange(s.Constraint); err != nil {
+			return fmt.Errorf("invalid version constraint '%s': %s", s.Constraint, err)
+		}
+	}
+	if _, err := s.Interval(); err != nil {
+		return fmt.Errorf("invalid check interval '%s': %s", s.CheckInterval, err)
+	}
+	return nil
+}
@@ -18,7 +18,9 @@ type UserConfig struct {
 	Token      string
 	Home       string
 	APIBaseURL string
-	settings   Configuration
+	// CredentialStore decides where the token is kept. See NewCredentialStore.
+	CredentialStore string
+	settings        Configuration
 }
 
 // NewUserConfig loads a user configuration if it exists.
@@ -35,7 +37,7 @@ func NewUserConfig() (*UserConfig, error) {
 // NewEmptyUserConfig creates a user configuration without loading it.
 func NewEmptyUserConfig() *UserConfig {
 	return &UserConfig{
-		Config:   New(Dir(), "user"),
+		Config:   New(UserDir(), "user"),
 		settings: NewConfiguration(),
 	}
 }
@@ -61,13 +63,32 @@ func (cfg *UserConfig) SetDefaults() {
 }
 
 // Write stores the config to disk.
+// It is saved the same way as the settings of the configure command.
 func (cfg *UserConfig) Write() error {
 	cfg.SetDefaults()
-	return Write(cfg)
+	v := viper.New()
+	v.Set("workspace", cfg.Workspace)
+	v.Set("token", cfg.Token)
+	v.Set("home", cfg.Home)
+	v.Set("apibaseurl", cfg.APIBaseURL)
+	if cfg.CredentialStore != "" {
+		v.Set(credentialStoreKey, cfg.CredentialStore)
+	}
+	return Configuration{UserViperConfig: v, Persister: FilePersister{Dir: cfg.dir}}.Save(cfg.name)
 }
 
 // Load reads a viper configuration into the config.
+// Older config files are upgraded to the current schema.
+// Environment variables override the settings in the file.
 func (cfg *UserConfig) Load(v *viper.Viper) error {
 	cfg.readIn(v)
+	v, err := migrateUserConfig(v)
+	if err != nil {
+		return err
+	}
+	BindEnv(v)
+	if err := LoadCredentials(v); err != nil {
+		return err
+	}
 	return v.Unmarshal(&cfg)
 }
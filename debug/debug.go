@@ -31,17 +31,28 @@ func Printf(format string, args ...interface{}) {
 	}
 }
 
-// DumpRequest dumps out the provided http.Request
+// DumpRequest dumps out the provided http.Request.
+// Credentials are masked, unless Unredacted is set.
 func DumpRequest(req *http.Request) {
 	if !Verbose {
 		return
 	}
 
-	var bodyCopy bytes.Buffer
-	body := io.TeeReader(req.Body, &bodyCopy)
-	req.Body = ioutil.NopCloser(body)
+	var body []byte
+	if req.Body != nil {
+		body = readBody(req.Body)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	r := *req
+	if !Unredacted {
+		r.Header = RedactHeader(req.Header)
+		r.URL = redactURL(req.URL)
+		body = RedactBody(req.Header, body)
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
 
-	dump, err := httputil.DumpRequest(req, req.ContentLength > 0)
+	dump, err := httputil.DumpRequest(&r, req.ContentLength > 0)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -50,21 +61,29 @@ func DumpRequest(req *http.Request) {
 	Println(string(dump))
 	Println("========================= END DumpRequest =========================")
 	Println("")
-
-	req.Body = ioutil.NopCloser(&bodyCopy)
 }
 
-// DumpResponse dumps out the provided http.Response
+// DumpResponse dumps out the provided http.Response.
+// Credentials are masked, unless Unredacted is set.
 func DumpResponse(res *http.Response) {
 	if !Verbose {
 		return
 	}
 
-	var bodyCopy bytes.Buffer
-	body := io.TeeReader(res.Body, &bodyCopy)
-	res.Body = ioutil.NopCloser(body)
+	body := readBody(res.Body)
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
 
-	dump, err := httputil.DumpResponse(res, res.ContentLength > 0)
+	r := *res
+	if !Unredacted {
+		r.Header = RedactHeader(res.Header)
+		body = RedactBody(res.Header, body)
+	}
+	if res.ContentLength > 0 {
+		r.ContentLength = int64(len(body))
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	dump, err := httputil.DumpResponse(&r, res.ContentLength > 0)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -73,6 +92,13 @@ func DumpResponse(res *http.Response) {
 	Println(string(dump))
 	Println("========================= END DumpResponse =========================")
 	Println("")
+}
 
-	res.Body = ioutil.NopCloser(body)
+func readBody(body io.ReadCloser) []byte {
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	body.Close()
+	return b
 }
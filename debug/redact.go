@@ -0,0 +1,123 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// Unredacted shows credentials in the debugging output.
+	// Only use it locally, never paste the output anywhere.
+	Unredacted bool
+)
+
+// sensitiveHeaders carry credentials.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// Redact masks all but the first four and last three characters of a token.
+// Short tokens are masked completely.
+func Redact(token string) string {
+	if len(token) <= 7 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + strings.Repeat("*", len(token)-7) + token[len(token)-3:]
+}
+
+// isSensitive decides whether a query parameter or JSON field holds a credential.
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "token") ||
+		strings.Contains(name, "password") ||
+		strings.Contains(name, "secret") ||
+		name == "key" || name == "api_key" || name == "apikey"
+}
+
+// RedactHeader masks the credentials in a copy of the header.
+func RedactHeader(h http.Header) http.Header {
+	redacted := http.Header{}
+	for key, values := range h {
+		redacted[key] = append([]string(nil), values...)
+	}
+	for _, key := range sensitiveHeaders {
+		values := redacted[http.CanonicalHeaderKey(key)]
+		for i, value := range values {
+			// Keep the scheme, e.g. Bearer, so that it's clear what was sent.
+			if fields := strings.SplitN(value, " ", 2); len(fields) == 2 && strings.HasSuffix(key, "Authorization") {
+				values[i] = fields[0] + " " + Redact(fields[1])
+			} else {
+				values[i] = Redact(value)
+			}
+		}
+	}
+	return redacted
+}
+
+// redactURL masks credentials in the query string of a copy of the URL.
+func redactURL(u *url.URL) *url.URL {
+	redacted := *u
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			redacted.User = url.UserPassword(u.User.Username(), Redact(password))
+		}
+	}
+	query := u.Query()
+	changed := false
+	for key, values := range query {
+		if !isSensitive(key) {
+			continue
+		}
+		for i, value := range values {
+			values[i] = Redact(value)
+		}
+		changed = true
+	}
+	if changed {
+		// Asterisks don't need escaping, and are easier to read as they are.
+		redacted.RawQuery = strings.Replace(query.Encode(), "%2A", "*", -1)
+	}
+	return &redacted
+}
+
+// redactBody masks credentials in a JSON body.
+// Anything that isn't JSON is left alone.
+func RedactBody(header http.Header, body []byte) []byte {
+	if !strings.Contains(header.Get("Content-Type"), "json") {
+		return body
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	if !redactJSON(v) {
+		return body
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return b
+}
+
+// redactJSON masks credentials in a decoded JSON value.
+// It reports whether anything was masked.
+func redactJSON(v interface{}) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if s, ok := value.(string); ok && isSensitive(key) {
+				v[key] = Redact(s)
+				changed = true
+				continue
+			}
+			changed = redactJSON(value) || changed
+		}
+	case []interface{}:
+		for _, value := range v {
+			changed = redactJSON(value) || changed
+		}
+	}
+	return changed
+}
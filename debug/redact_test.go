@@ -0,0 +1,79 @@
+package debug
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	fakeToken := "1a11111aaaa111aa1a11111a11111aa1"
+	expected := "1a11*************************aa1"
+
+	assert.Equal(t, expected, Redact(fakeToken))
+
+	// Short tokens don't give anything away.
+	assert.Equal(t, "*****", Redact("abcde"))
+}
+
+func TestDumpRedactsCredentials(t *testing.T) {
+	defer func(v, u bool) {
+		Verbose, Unredacted = v, u
+		output = ioutil.Discard
+	}(Verbose, Unredacted)
+
+	token := "1a11111aaaa111aa1a11111a11111aa1"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"user": {"handle": "alice", "api_token": "%s"}}`, token)
+	}))
+	defer ts.Close()
+
+	dump := func() string {
+		var buf bytes.Buffer
+		output = &buf
+		Verbose = true
+
+		body := fmt.Sprintf(`{"token": "%s", "track": "go"}`, token)
+		req, err := http.NewRequest("POST", ts.URL+"/validate?token="+token+"&track=go", strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Cookie", "session="+token)
+		req.Header.Set("Content-Type", "application/json")
+
+		DumpRequest(req)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+		DumpResponse(res)
+
+		// The bodies can still be read after dumping them.
+		b, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), token)
+		return buf.String()
+	}
+
+	Unredacted = false
+	s := dump()
+	assert.NotContains(t, s, token)
+	assert.Contains(t, s, "Authorization: Bearer "+Redact(token))
+	assert.Contains(t, s, "Cookie: "+Redact("session="+token))
+	assert.Contains(t, s, "token="+Redact(token))
+	assert.Contains(t, s, "track=go")
+	assert.Contains(t, s, `"token":"`+Redact(token)+`"`)
+	assert.Contains(t, s, `"api_token":"`+Redact(token)+`"`)
+	assert.Contains(t, s, `"handle":"alice"`)
+
+	Unredacted = true
+	s = dump()
+	assert.Contains(t, s, "Authorization: Bearer "+token)
+	assert.Contains(t, s, "token="+token)
+	assert.Contains(t, s, `"api_token": "`+token+`"`)
+}
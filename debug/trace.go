@@ -0,0 +1,344 @@
+package debug
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// harVersion is the version of the HAR format that traces are written in.
+const harVersion = "1.2"
+
+// Tracer records HTTP requests and responses in a file.
+// If the file name ends in .har the trace is a HAR 1.2 archive,
+// which can be loaded in browser developer tools.
+// Otherwise every request is written as a line of JSON as soon as it completes.
+// Headers and query strings are redacted, unless Unredacted is set.
+type Tracer struct {
+	mu      sync.Mutex
+	f       *os.File
+	har     bool
+	entries []harEntry
+	// Creator is recorded in HAR archives.
+	Creator string
+	// Version is recorded in HAR archives.
+	Version string
+}
+
+// NewTracer creates the trace file.
+func NewTracer(path string) (*Tracer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Tracer{
+		f:       f,
+		har:     strings.EqualFold(filepath.Ext(path), ".har"),
+		entries: []harEntry{},
+	}, nil
+}
+
+// Transport records everything that goes through the next transport.
+// If next is nil, http.DefaultTransport is used.
+func (t *Tracer) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &traceTransport{tracer: t, next: next}
+}
+
+// Close finishes the trace. HAR archives are only written at this point.
+func (t *Tracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.har {
+		doc := harDocument{Log: harLog{
+			Version: harVersion,
+			Creator: harCreator{Name: t.Creator, Version: t.Version},
+			Entries: t.entries,
+		}}
+		enc := json.NewEncoder(t.f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			t.f.Close()
+			return err
+		}
+	}
+	return t.f.Close()
+}
+
+func (t *Tracer) record(entry harEntry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.har {
+		t.entries = append(t.entries, entry)
+		return nil
+	}
+	return json.NewEncoder(t.f).Encode(entry)
+}
+
+type traceTransport struct {
+	tracer *Tracer
+	next   http.RoundTripper
+}
+
+// RoundTrip performs the request and records it.
+// The response body is read right away, so that its size
+// and the time it took to receive it are known.
+func (tt *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var timer requestTimer
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timer.trace()))
+
+	timer.mark(&timer.start)
+	res, err := tt.next.RoundTrip(req)
+
+	entry := newHAREntry(req, timer.start)
+	if err != nil {
+		entry.Error = err.Error()
+		timer.mark(&timer.end)
+		entry.setTimings(&timer)
+		tt.tracer.record(entry)
+		return nil, err
+	}
+
+	body, readErr := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	timer.mark(&timer.end)
+
+	entry.Response = newHARResponse(res, len(body))
+	entry.setTimings(&timer)
+	if readErr != nil {
+		entry.Error = readErr.Error()
+	}
+	tt.tracer.record(entry)
+	if readErr != nil {
+		return nil, readErr
+	}
+	return res, nil
+}
+
+// requestTimer collects the moments that make up the timings of a HAR entry.
+type requestTimer struct {
+	// mu guards the moments, since the client may report them from other goroutines.
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	end          time.Time
+}
+
+func (rt *requestTimer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { rt.mark(&rt.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { rt.mark(&rt.dnsDone) },
+		ConnectStart:         func(string, string) { rt.mark(&rt.connectStart) },
+		ConnectDone:          func(string, string, error) { rt.mark(&rt.connectDone) },
+		TLSHandshakeStart:    func() { rt.mark(&rt.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { rt.mark(&rt.tlsDone) },
+		GotConn:              func(httptrace.GotConnInfo) { rt.mark(&rt.gotConn) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { rt.mark(&rt.wroteRequest) },
+		GotFirstResponseByte: func() { rt.mark(&rt.firstByte) },
+	}
+}
+
+// mark records the current time as the given moment.
+func (rt *requestTimer) mark(moment *time.Time) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	*moment = time.Now()
+}
+
+// span is the time between two moments in milliseconds, or -1 if either is unknown.
+func span(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() {
+		return -1
+	}
+	return float64(to.Sub(from)) / float64(time.Millisecond)
+}
+
+// harDocument is a HAR 1.2 archive. See http://www.softwareishard.com/blog/har-12-spec/.
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	// Error explains why there is no response. Custom fields start with an underscore.
+	Error string `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []harNVP    `json:"cookies"`
+	Headers     []harNVP    `json:"headers"`
+	QueryString []harNVP    `json:"queryString"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+	PostData    *harContent `json:"postData,omitempty"`
+}
+
+type harResponse struct {
+	Status      int        `json:"status"`
+	StatusText  string     `json:"statusText"`
+	HTTPVersion string     `json:"httpVersion"`
+	Cookies     []harNVP   `json:"cookies"`
+	Headers     []harNVP   `json:"headers"`
+	Content     harContent `json:"content"`
+	RedirectURL string     `json:"redirectURL"`
+	HeadersSize int        `json:"headersSize"`
+	BodySize    int        `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// harNVP is a name and value pair.
+type harNVP struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func newHAREntry(req *http.Request, start time.Time) harEntry {
+	header, u := req.Header, req.URL
+	if !Unredacted {
+		header, u = RedactHeader(req.Header), redactURL(req.URL)
+	}
+
+	query := []harNVP{}
+	for name, values := range u.Query() {
+		for _, value := range values {
+			query = append(query, harNVP{Name: name, Value: value})
+		}
+	}
+
+	entry := harEntry{
+		StartedDateTime: start,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         u.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []harNVP{},
+			Headers:     harHeaders(header),
+			QueryString: query,
+			HeadersSize: -1,
+			BodySize:    req.ContentLength,
+		},
+		Response: harResponse{
+			Cookies: []harNVP{},
+			Headers: []harNVP{},
+		},
+	}
+	if req.ContentLength > 0 {
+		entry.Request.PostData = &harContent{
+			Size:     int(req.ContentLength),
+			MimeType: req.Header.Get("Content-Type"),
+		}
+	}
+	return entry
+}
+
+func newHARResponse(res *http.Response, size int) harResponse {
+	header := res.Header
+	if !Unredacted {
+		header = RedactHeader(res.Header)
+	}
+	location, _ := res.Location()
+	redirect := ""
+	if location != nil {
+		redirect = location.String()
+	}
+	return harResponse{
+		Status:      res.StatusCode,
+		StatusText:  http.StatusText(res.StatusCode),
+		HTTPVersion: res.Proto,
+		Cookies:     []harNVP{},
+		Headers:     harHeaders(header),
+		Content: harContent{
+			Size:     size,
+			MimeType: res.Header.Get("Content-Type"),
+		},
+		RedirectURL: redirect,
+		HeadersSize: -1,
+		BodySize:    size,
+	}
+}
+
+func harHeaders(h http.Header) []harNVP {
+	headers := []harNVP{}
+	for name, values := range h {
+		for _, value := range values {
+			headers = append(headers, harNVP{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+func (e *harEntry) setTimings(rt *requestTimer) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	e.Time = span(rt.start, rt.end)
+	e.Timings = harTimings{
+		Blocked: -1,
+		DNS:     span(rt.dnsStart, rt.dnsDone),
+		Connect: span(rt.connectStart, rt.connectDone),
+		SSL:     span(rt.tlsStart, rt.tlsDone),
+		Send:    span(rt.gotConn, rt.wroteRequest),
+		Wait:    span(rt.wroteRequest, rt.firstByte),
+		Receive: span(rt.firstByte, rt.end),
+	}
+	// HAR requires send, wait, and receive.
+	for _, t := range []*float64{&e.Timings.Send, &e.Timings.Wait, &e.Timings.Receive} {
+		if *t < 0 {
+			*t = 0
+		}
+	}
+}
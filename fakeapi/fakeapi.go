@@ -0,0 +1,372 @@
+// Package fakeapi is a stand-in for the Exercism API, for tests and local development.
+//
+// It serves everything from a directory of fixtures:
+//
+//	tracks/TRACK_ID.json          a track
+//	solutions/SOLUTION_ID.json    a solution
+//	files/SOLUTION_ID/PATH        the files of a solution
+//
+// The file list and file download URL of a solution are filled in from its files,
+// and the track of its exercise from the track fixture.
+// Submissions are saved in submissions/SOLUTION_ID/ITERATION.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/exercism/cli/api"
+)
+
+// DefaultToken is the token the server accepts, unless another one is configured.
+const DefaultToken = "fake-token"
+
+// Server is a fake Exercism API.
+type Server struct {
+	// Dir is the directory with the fixtures.
+	Dir string
+	// Token is the only API token that is authorized.
+	Token string
+
+	// mu serializes submissions.
+	mu  sync.Mutex
+	mux *http.ServeMux
+}
+
+// New creates a server for the fixtures in dir.
+// If the token is empty, DefaultToken is used.
+func New(dir, token string) *Server {
+	if token == "" {
+		token = DefaultToken
+	}
+	s := &Server{Dir: dir, Token: token, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/ping", s.ping)
+	s.mux.HandleFunc("/validate_token", s.authorized(s.validateToken))
+	s.mux.HandleFunc("/solutions/", s.authorized(s.solution))
+	s.mux.HandleFunc("/tracks/", s.authorized(s.track))
+	s.mux.HandleFunc("/files/", s.authorized(s.file))
+	return s
+}
+
+// ServeHTTP answers API requests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) ping(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status": map[string]string{"website": "operational", "database": "operational"},
+	})
+}
+
+func (s *Server) validateToken(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// authorized rejects requests that don't have the right token.
+func (s *Server) authorized(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.Token {
+			writeError(w, http.StatusUnauthorized, &api.Error{
+				Type:    "unauthorized",
+				Message: "The token is invalid",
+			})
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) solution(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/solutions/")
+	switch {
+	case r.Method == "GET" && id == "latest":
+		s.latestSolution(w, r)
+	case r.Method == "GET":
+		solution, err := s.loadSolution(id, r)
+		if err != nil {
+			writeNotFound(w, "Solution not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"solution": solution})
+	case r.Method == "PATCH":
+		s.submit(w, r, id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// latestSolution finds the solution to an exercise.
+// If no track is given and the exercise is on more than one track,
+// it's up to the client to pick one.
+func (s *Server) latestSolution(w http.ResponseWriter, r *http.Request) {
+	exercise := r.URL.Query().Get("exercise_id")
+	track := r.URL.Query().Get("track_id")
+
+	ids, err := s.solutionIDs()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, &api.Error{Type: "internal", Message: err.Error()})
+		return
+	}
+	var matches []*api.Solution
+	for _, id := range ids {
+		solution, err := s.loadSolution(id, r)
+		if err != nil {
+			continue
+		}
+		if !solution.User.IsRequester || solution.Exercise.ID != exercise {
+			continue
+		}
+		if track != "" && solution.Exercise.Track.ID != track {
+			continue
+		}
+		matches = append(matches, solution)
+	}
+
+	switch len(matches) {
+	case 0:
+		writeNotFound(w, "Exercise not found")
+	case 1:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"solution": matches[0]})
+	default:
+		apiErr := &api.Error{
+			Type:    "track_ambiguous",
+			Message: "Please specify a track ID",
+		}
+		for _, solution := range matches {
+			apiErr.PossibleTrackIDs = append(apiErr.PossibleTrackIDs, solution.Exercise.Track.ID)
+		}
+		writeError(w, http.StatusBadRequest, apiErr)
+	}
+}
+
+// submit saves the uploaded files as a new iteration.
+func (s *Server) submit(w http.ResponseWriter, r *http.Request, id string) {
+	solution, err := s.loadSolution(id, r)
+	if err != nil {
+		writeNotFound(w, "Solution not found")
+		return
+	}
+	if !solution.User.IsRequester {
+		writeError(w, http.StatusForbidden, &api.Error{
+			Type:    "forbidden",
+			Message: "You can only submit your own solutions",
+		})
+		return
+	}
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, &api.Error{Type: "invalid_submission", Message: err.Error()})
+		return
+	}
+	files := r.MultipartForm.File["files[]"]
+	if len(files) == 0 {
+		writeError(w, http.StatusBadRequest, &api.Error{Type: "invalid_submission", Message: "No files submitted"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.nextIterationDir(id)
+	if err == nil {
+		for _, fh := range files {
+			if err = saveUpload(dir, fh); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, &api.Error{Type: "internal", Message: err.Error()})
+		return
+	}
+
+	submittedAt := time.Now().UTC().Format(time.RFC3339)
+	solution.Iteration.SubmittedAt = &submittedAt
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"solution": solution})
+}
+
+func (s *Server) track(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/tracks/")
+	track, err := s.loadTrack(id)
+	if err != nil {
+		writeNotFound(w, "Track not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"track": track})
+}
+
+func (s *Server) file(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean(strings.TrimPrefix(r.URL.Path, "/files/"))
+	if strings.HasPrefix(name, "..") {
+		writeNotFound(w, "File not found")
+		return
+	}
+	f, err := os.Open(filepath.Join(s.Dir, "files", filepath.FromSlash(name)))
+	if err != nil {
+		writeNotFound(w, "File not found")
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		writeNotFound(w, "File not found")
+		return
+	}
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", info.Size()))
+	io.Copy(w, f)
+}
+
+// loadSolution reads a solution fixture, and fills in what can be derived.
+func (s *Server) loadSolution(id string, r *http.Request) (*api.Solution, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, fmt.Errorf("invalid solution ID '%s'", id)
+	}
+	var solution api.Solution
+	if err := readJSON(filepath.Join(s.Dir, "solutions", id+".json"), &solution); err != nil {
+		return nil, err
+	}
+	if solution.ID == "" {
+		solution.ID = id
+	}
+	if track, err := s.loadTrack(solution.Exercise.Track.ID); err == nil {
+		solution.Exercise.Track = *track
+	}
+	files, err := s.solutionFiles(id)
+	if err != nil {
+		return nil, err
+	}
+	solution.Files = files
+	solution.FileDownloadBaseURL = fmt.Sprintf("http://%s/files/%s/", r.Host, id)
+	return &solution, nil
+}
+
+func (s *Server) loadTrack(id string) (*api.Track, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, fmt.Errorf("invalid track ID '%s'", id)
+	}
+	var track api.Track
+	if err := readJSON(filepath.Join(s.Dir, "tracks", id+".json"), &track); err != nil {
+		return nil, err
+	}
+	if track.ID == "" {
+		track.ID = id
+	}
+	return &track, nil
+}
+
+// solutionIDs lists the solution fixtures.
+func (s *Server) solutionIDs() ([]string, error) {
+	infos, err := ioutil.ReadDir(filepath.Join(s.Dir, "solutions"))
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, info := range infos {
+		if filepath.Ext(info.Name()) == ".json" {
+			ids = append(ids, strings.TrimSuffix(info.Name(), ".json"))
+		}
+	}
+	return ids, nil
+}
+
+// solutionFiles lists the files of a solution as slash separated paths.
+func (s *Server) solutionFiles(id string) ([]string, error) {
+	root := filepath.Join(s.Dir, "files", id)
+	files := []string{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == root {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// nextIterationDir creates the directory for the next submission of a solution.
+func (s *Server) nextIterationDir(id string) (string, error) {
+	root := filepath.Join(s.Dir, "submissions", id)
+	if err := os.MkdirAll(root, os.FileMode(0755)); err != nil {
+		return "", err
+	}
+	infos, err := ioutil.ReadDir(root)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(root, fmt.Sprintf("%d", len(infos)+1))
+	return dir, os.Mkdir(dir, os.FileMode(0755))
+}
+
+// saveUpload writes an uploaded file into the directory.
+// The name can't point outside of the directory.
+func saveUpload(dir string, fh *multipart.FileHeader) error {
+	// Newer versions of Go drop the directory from fh.Filename.
+	name := fh.Filename
+	if _, params, err := mime.ParseMediaType(fh.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		name = params["filename"]
+	}
+	name = path.Clean("/" + filepath.ToSlash(name))
+	dst := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dst), os.FileMode(0755)); err != nil {
+		return err
+	}
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readJSON(path string, v interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, apiErr *api.Error) {
+	writeJSON(w, status, map[string]*api.Error{"error": apiErr})
+}
+
+func writeNotFound(w http.ResponseWriter, message string) {
+	writeError(w, http.StatusNotFound, &api.Error{Type: "resource_not_found", Message: message})
+}
@@ -0,0 +1,156 @@
+package fakeapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/exercism/cli/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtures copies the fixtures into a temporary directory,
+// so that submissions don't end up in the repository.
+func fixtures(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "fakeapi")
+	assert.NoError(t, err)
+
+	src := filepath.Join("..", "fixtures", "fakeapi")
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel == "submissions" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dir, rel), os.FileMode(0755))
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, rel), b, os.FileMode(0644))
+	})
+	assert.NoError(t, err)
+	return dir
+}
+
+func newTestClient(t *testing.T, token string) (*api.Client, string, func()) {
+	dir := fixtures(t)
+	ts := httptest.NewServer(New(dir, ""))
+	client, err := api.NewClient(token, ts.URL)
+	assert.NoError(t, err)
+	client.Retry.MaxAttempts = 1
+	return client, dir, func() {
+		ts.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPingAndToken(t *testing.T) {
+	client, _, done := newTestClient(t, DefaultToken)
+	defer done()
+
+	assert.NoError(t, client.Ping())
+	ok, err := client.TokenIsValid()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	client.Token = "bogus"
+	assert.NoError(t, client.Ping())
+	ok, err = client.TokenIsValid()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = client.GetSolution("ruby-bob")
+	if assert.IsType(t, &api.Error{}, err) {
+		assert.Equal(t, http.StatusUnauthorized, err.(*api.Error).StatusCode)
+	}
+}
+
+func TestGetLatestSolution(t *testing.T) {
+	client, _, done := newTestClient(t, DefaultToken)
+	defer done()
+
+	_, err := client.GetLatestSolution("", "hello-world")
+	if assert.IsType(t, &api.Error{}, err) {
+		apiErr := err.(*api.Error)
+		assert.Equal(t, "track_ambiguous", apiErr.Type)
+		assert.Equal(t, []string{"python", "ruby"}, apiErr.PossibleTrackIDs)
+	}
+
+	solution, err := client.GetLatestSolution("ruby", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, "ruby-hello-world", solution.ID)
+	assert.Equal(t, "Ruby", solution.Exercise.Track.Language)
+	assert.Equal(t, []string{"README.md", "hello_world.rb", "hello_world_test.rb"}, solution.Files)
+	assert.Equal(t, client.APIBaseURL+"/files/ruby-hello-world/", solution.FileDownloadBaseURL)
+
+	// Other people's solutions are not the latest solution.
+	_, err = client.GetLatestSolution("", "bob")
+	if assert.IsType(t, &api.Error{}, err) {
+		assert.Equal(t, http.StatusNotFound, err.(*api.Error).StatusCode)
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	client, _, done := newTestClient(t, DefaultToken)
+	defer done()
+
+	solution, err := client.GetSolution("ruby-bob")
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", solution.User.Handle)
+	assert.False(t, solution.User.IsRequester)
+
+	req, err := client.NewRequest("GET", solution.FileDownloadBaseURL+"bob.rb", nil)
+	assert.NoError(t, err)
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "Whatever.")
+}
+
+func TestSubmit(t *testing.T) {
+	client, dir, done := newTestClient(t, DefaultToken)
+	defer done()
+
+	files := []api.SolutionFile{
+		{Name: "hello_world.rb", Content: strings.NewReader("first")},
+		{Name: "lib/helper.rb", Content: strings.NewReader("helper")},
+	}
+	assert.NoError(t, client.UpdateSolution("ruby-hello-world", files))
+	files = []api.SolutionFile{{Name: "hello_world.rb", Content: strings.NewReader("second")}}
+	assert.NoError(t, client.UpdateSolution("ruby-hello-world", files))
+
+	// Every submission is saved as a new iteration.
+	iterations := filepath.Join(dir, "submissions", "ruby-hello-world")
+	for path, expected := range map[string]string{
+		"1/hello_world.rb": "first",
+		"1/lib/helper.rb":  "helper",
+		"2/hello_world.rb": "second",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(iterations, filepath.FromSlash(path)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(b))
+	}
+
+	err := client.UpdateSolution("ruby-bob", files)
+	if assert.IsType(t, &api.Error{}, err) {
+		assert.Equal(t, http.StatusForbidden, err.(*api.Error).StatusCode)
+	}
+	err = client.UpdateSolution("no-such-solution", files)
+	if assert.IsType(t, &api.Error{}, err) {
+		assert.Equal(t, http.StatusNotFound, err.(*api.Error).StatusCode)
+	}
+}
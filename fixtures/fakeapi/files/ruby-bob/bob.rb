@@ -0,0 +1,5 @@
+class Bob
+  def self.hey(remark)
+    'Whatever.'
+  end
+end
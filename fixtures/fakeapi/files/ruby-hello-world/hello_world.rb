@@ -0,0 +1,4 @@
+class HelloWorld
+  def self.hello
+  end
+end
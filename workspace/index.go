@@ -0,0 +1,294 @@
+package workspace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	indexDirname  = ".exercism"
+	indexFilename = "index.json"
+)
+
+// Index is an on-disk record of the directories in a workspace.
+// It lets us find exercises without walking the whole workspace tree.
+//
+// The index remembers the modification time of every directory it looked
+// inside of. Adding or removing a directory changes the modification time
+// of its parent, so if any of these have changed the index is stale.
+// The contents of solution directories are not indexed, which means that
+// editing solution files doesn't invalidate it.
+type Index struct {
+	// Dirs lists every directory that may be an exercise, relative to the workspace.
+	Dirs []string `json:"dirs"`
+	// Solutions describes the directories that contain solution metadata.
+	Solutions []IndexEntry `json:"solutions"`
+	// ModTimes records the modification time of each directory that was scanned.
+	ModTimes map[string]int64 `json:"mtimes"`
+}
+
+// IndexEntry is a solution directory in the index.
+type IndexEntry struct {
+	Dir         string `json:"dir"`
+	Track       string `json:"track"`
+	Exercise    string `json:"exercise"`
+	ID          string `json:"id"`
+	Handle      string `json:"handle"`
+	IsRequester bool   `json:"is_requester"`
+}
+
+// IndexPath is the location of the workspace index.
+// It is always in the root of the whole workspace.
+func (ws Workspace) IndexPath() string {
+	return filepath.Join(ws.indexRoot(), indexDirname, indexFilename)
+}
+
+// RebuildIndex walks the workspace and stores a fresh index.
+func (ws Workspace) RebuildIndex() (*Index, error) {
+	// Make sure the index directory exists before looking at the workspace,
+	// since creating it changes the modification time of the workspace root.
+	if err := os.MkdirAll(filepath.Dir(ws.IndexPath()), os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+	idx, err := ws.buildIndex()
+	if err != nil {
+		return nil, err
+	}
+	return idx, idx.write(ws.IndexPath())
+}
+
+// index loads the workspace index.
+// The index is built the first time it's needed, and rebuilt whenever it is stale.
+// If it can't be built, e.g. because the workspace is read-only,
+// it returns nil, and the caller should walk the workspace.
+func (ws Workspace) index() *Index {
+	idx, err := readIndex(ws.IndexPath())
+	if err == nil && idx.isFresh(ws.indexRoot()) {
+		return idx
+	}
+	idx, err = ws.RebuildIndex()
+	if err != nil {
+		return nil
+	}
+	return idx
+}
+
+func (ws Workspace) buildIndex() (*Index, error) {
+	idx := &Index{ModTimes: map[string]int64{}}
+	root := ws.indexRoot()
+
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			idx.ModTimes["."] = info.ModTime().UnixNano()
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// If it's a symlink, follow it, then get the file info of the target.
+		// We don't look inside of symlinked directories, just like Locate.
+		descend := true
+		if info.Mode()&os.ModeSymlink == os.ModeSymlink {
+			src, err := filepath.EvalSymlinks(path)
+			if err == nil {
+				path = src
+			}
+			info, err = os.Lstat(path)
+			if err != nil {
+				return err
+			}
+			descend = false
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		idx.addDir(rel)
+
+		if _, err := os.Lstat(filepath.Join(path, solutionFilename)); err == nil {
+			s, err := NewSolution(path)
+			if err != nil {
+				return err
+			}
+			idx.addSolution(rel, s)
+			// The contents of a solution are not indexed.
+			return filepath.SkipDir
+		}
+		if descend {
+			idx.ModTimes[rel] = info.ModTime().UnixNano()
+		}
+		return nil
+	}
+	if err := filepath.Walk(root, walkFn); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// ByExercise finds the indexed solutions to the given exercise.
+func (idx *Index) ByExercise(exercise string) []IndexEntry {
+	return idx.filter(func(e IndexEntry) bool { return e.Exercise == exercise })
+}
+
+// ByTrack finds the indexed solutions on the given track.
+func (idx *Index) ByTrack(track string) []IndexEntry {
+	return idx.filter(func(e IndexEntry) bool { return e.Track == track })
+}
+
+// ByID finds the indexed solution with the given ID.
+func (idx *Index) ByID(id string) (IndexEntry, bool) {
+	entries := idx.filter(func(e IndexEntry) bool { return e.ID == id })
+	if len(entries) == 0 {
+		return IndexEntry{}, false
+	}
+	return entries[0], true
+}
+
+func (idx *Index) filter(fn func(IndexEntry) bool) []IndexEntry {
+	var entries []IndexEntry
+	for _, entry := range idx.Solutions {
+		if fn(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (idx *Index) addSolution(rel string, s *Solution) {
+	entry := IndexEntry{
+		Dir:         rel,
+		Track:       s.Track,
+		Exercise:    s.Exercise,
+		ID:          s.ID,
+		Handle:      s.Handle,
+		IsRequester: s.IsRequester,
+	}
+	for i, e := range idx.Solutions {
+		if e.Dir == rel {
+			idx.Solutions[i] = entry
+			return
+		}
+	}
+	idx.Solutions = append(idx.Solutions, entry)
+	idx.addDir(rel)
+}
+
+func (idx *Index) addDir(rel string) {
+	i := sort.SearchStrings(idx.Dirs, rel)
+	if i < len(idx.Dirs) && idx.Dirs[i] == rel {
+		return
+	}
+	idx.Dirs = append(idx.Dirs, "")
+	copy(idx.Dirs[i+1:], idx.Dirs[i:])
+	idx.Dirs[i] = rel
+}
+
+func (idx *Index) isFresh(root string) bool {
+	if len(idx.ModTimes) == 0 {
+		return false
+	}
+	for rel, mtime := range idx.ModTimes {
+		info, err := os.Lstat(filepath.Join(root, rel))
+		if err != nil || info.ModTime().UnixNano() != mtime {
+			return false
+		}
+	}
+	return true
+}
+
+func readIndex(path string) (*Index, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var idx Index
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return nil, err
+	}
+	return &idx, nil
+}
+
+func (idx *Index) write(path string) error {
+	b, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, os.FileMode(0644))
+}
+
+// UpdateIndex records a newly written solution in the workspace index.
+// Without this, writing a solution makes the index stale, and the next
+// lookup rebuilds it. If the workspace doesn't have an index yet, nothing happens.
+func (ws Workspace) UpdateIndex(s *Solution) error {
+	root := ws.indexRoot()
+	dir := resolve(s.Dir)
+	if dir == root || !contains(root, dir) {
+		return nil
+	}
+	path := ws.IndexPath()
+	idx, err := readIndex(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// If anything else changed, start over.
+	// If the solution directory was just created it will have changed
+	// the modification times of the directories above it, so we leave
+	// those out.
+	for rel, mtime := range idx.ModTimes {
+		d := filepath.Join(root, rel)
+		if strings.HasPrefix(dir, d+string(os.PathSeparator)) {
+			continue
+		}
+		info, err := os.Lstat(d)
+		if err != nil || info.ModTime().UnixNano() != mtime {
+			_, err := ws.RebuildIndex()
+			return err
+		}
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	idx.addSolution(rel, s)
+
+	// Any directories between the workspace and the solution
+	// may have been created along with it.
+	for d := filepath.Dir(dir); ; d = filepath.Dir(d) {
+		info, err := os.Lstat(d)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, d)
+		if err != nil {
+			return err
+		}
+		if rel != "." {
+			idx.addDir(rel)
+		}
+		idx.ModTimes[rel] = info.ModTime().UnixNano()
+		if d == root {
+			break
+		}
+	}
+	return idx.write(path)
+}
@@ -0,0 +1,198 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "workspace-index")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	ws, err := New(tmpDir)
+	assert.NoError(t, err)
+
+	writeSolution := func(dir string, s *Solution) {
+		path := filepath.Join(ws.Dir, dir)
+		err := os.MkdirAll(path, os.FileMode(0755))
+		assert.NoError(t, err)
+		err = s.Write(path)
+		assert.NoError(t, err)
+		err = ws.UpdateIndex(s)
+		assert.NoError(t, err)
+	}
+	writeSolution(filepath.Join("go", "leap"), &Solution{ID: "aaa", Track: "go", Exercise: "leap", IsRequester: true})
+	writeSolution(filepath.Join("users", "alice", "go", "leap"), &Solution{ID: "bbb", Track: "go", Exercise: "leap", Handle: "alice"})
+
+	// There's no index until it is needed.
+	_, err = os.Stat(ws.IndexPath())
+	assert.True(t, os.IsNotExist(err))
+
+	idx := ws.index()
+	assert.NotNil(t, idx)
+	assert.Equal(t, 2, len(idx.Solutions))
+	assert.Equal(t, 2, len(idx.ByExercise("leap")))
+	assert.Equal(t, 2, len(idx.ByTrack("go")))
+	entry, ok := idx.ByID("bbb")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join("users", "alice", "go", "leap"), entry.Dir)
+
+	// The index is used to locate exercises.
+	paths, err := ws.Locate("leap")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(ws.Dir, "go", "leap"),
+		filepath.Join(ws.Dir, "users", "alice", "go", "leap"),
+	}, paths)
+
+	// Writing a solution updates the index, without making it stale.
+	writeSolution(filepath.Join("ruby", "bob"), &Solution{ID: "ccc", Track: "ruby", Exercise: "bob", IsRequester: true})
+	idx, err = readIndex(ws.IndexPath())
+	assert.NoError(t, err)
+	assert.True(t, idx.isFresh(ws.Dir))
+	_, ok = idx.ByID("ccc")
+	assert.True(t, ok)
+
+	// Adding a directory by hand makes the index stale.
+	// Make sure the modification time is different, even on coarse file systems.
+	later := time.Now().Add(time.Minute)
+	err = os.MkdirAll(filepath.Join(ws.Dir, "go", "leap-2"), os.FileMode(0755))
+	assert.NoError(t, err)
+	err = os.Chtimes(filepath.Join(ws.Dir, "go"), later, later)
+	assert.NoError(t, err)
+	idx, err = readIndex(ws.IndexPath())
+	assert.NoError(t, err)
+	assert.False(t, idx.isFresh(ws.Dir))
+
+	// Locate notices, and rebuilds the index.
+	paths, err = ws.Locate("leap")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(paths))
+	idx, err = readIndex(ws.IndexPath())
+	assert.NoError(t, err)
+	assert.True(t, idx.isFresh(ws.Dir))
+
+	solutions, err := ws.Solutions()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(solutions))
+}
+
+func TestIndexSymlinkedWorkspace(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "workspace-index")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	dir := filepath.Join(tmpDir, "workspace")
+	link := filepath.Join(tmpDir, "link")
+	err = os.MkdirAll(filepath.Join(dir, "go", "leap"), os.FileMode(0755))
+	assert.NoError(t, err)
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skip("symlinks are not supported")
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	assert.NoError(t, err)
+
+	// Paths are below the resolved workspace, whether or not they come from the index.
+	ws := Workspace{Dir: link}
+	expected := []string{filepath.Join(root, "go", "leap")}
+
+	// Keep the index from being built, so that the workspace is walked.
+	blocker := filepath.Join(dir, indexDirname)
+	err = ioutil.WriteFile(blocker, nil, os.FileMode(0644))
+	assert.NoError(t, err)
+	assert.Nil(t, ws.index())
+	paths, err := ws.Locate("leap")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, paths)
+
+	err = os.Remove(blocker)
+	assert.NoError(t, err)
+	assert.NotNil(t, ws.index())
+	paths, err = ws.Locate("leap")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, paths)
+
+	// Solutions outside of the workspace are not indexed.
+	s := &Solution{ID: "aaa", Exercise: "leap", Dir: tmpDir}
+	assert.NoError(t, ws.UpdateIndex(s))
+	idx, err := readIndex(ws.IndexPath())
+	assert.NoError(t, err)
+	assert.Empty(t, idx.Solutions)
+}
+
+func TestIndexIsKeptInTheWorkspaceRoot(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "workspace-index")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	root, err := New(tmpDir)
+	assert.NoError(t, err)
+	err = os.MkdirAll(filepath.Join(root.Dir, "go"), os.FileMode(0755))
+	assert.NoError(t, err)
+	track, err := root.In("go")
+	assert.NoError(t, err)
+	assert.Equal(t, root.IndexPath(), track.IndexPath())
+
+	path, err := track.SolutionPath("leap", "aaa")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root.Dir, "go", "leap"), path)
+	_, err = os.Stat(filepath.Join(track.Dir, indexDirname))
+	assert.True(t, os.IsNotExist(err))
+
+	err = os.MkdirAll(path, os.FileMode(0755))
+	assert.NoError(t, err)
+	s := &Solution{ID: "aaa", Track: "go", Exercise: "leap", IsRequester: true}
+	assert.NoError(t, s.Write(path))
+	assert.NoError(t, track.UpdateIndex(s))
+
+	idx, err := readIndex(root.IndexPath())
+	assert.NoError(t, err)
+	_, ok := idx.ByID("aaa")
+	assert.True(t, ok)
+	assert.True(t, idx.isFresh(root.Dir))
+
+	// Parts of the workspace only see their own exercises.
+	err = os.MkdirAll(filepath.Join(root.Dir, "ruby", "leap"), os.FileMode(0755))
+	assert.NoError(t, err)
+	paths, err := track.Locate("leap")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{path}, paths)
+	paths, err = root.Locate("leap")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(paths))
+}
+
+func TestLocateFallsBackToWalking(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "workspace-index")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	ws, err := New(tmpDir)
+	assert.NoError(t, err)
+	path := filepath.Join(ws.Dir, "go", "leap")
+	err = os.MkdirAll(path, os.FileMode(0755))
+	assert.NoError(t, err)
+	assert.NoError(t, (&Solution{ID: "aaa", Track: "go", Exercise: "leap"}).Write(path))
+	assert.NotNil(t, ws.index())
+
+	// The contents of solutions aren't indexed, but exercises copied there are still found.
+	copied := filepath.Join(path, "copies", "bob")
+	err = os.MkdirAll(copied, os.FileMode(0755))
+	assert.NoError(t, err)
+	idx, err := readIndex(ws.IndexPath())
+	assert.NoError(t, err)
+	assert.True(t, idx.isFresh(ws.Dir))
+
+	paths, err := ws.Locate("bob")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{copied}, paths)
+
+	_, err = ws.Locate("pig")
+	assert.True(t, IsNotExist(err))
+}
@@ -0,0 +1,79 @@
+package workspace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/exercism/cli/visibility"
+)
+
+const pendingFilename = ".pending.json"
+
+// Pending records the files of a solution that still need to be downloaded.
+// It lets a download that only partially succeeded be picked up again
+// the next time the solution is downloaded.
+type Pending struct {
+	Files []string `json:"files"`
+}
+
+// NewPending reads the pending downloads from the given solution directory.
+// If nothing is pending, the list is empty.
+func NewPending(dir string) (*Pending, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, pendingFilename))
+	if os.IsNotExist(err) {
+		return &Pending{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p Pending
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Add marks the given files as pending.
+func (p *Pending) Add(files ...string) {
+	m := map[string]bool{}
+	for _, file := range p.Files {
+		m[file] = true
+	}
+	for _, file := range files {
+		if !m[file] {
+			p.Files = append(p.Files, file)
+			m[file] = true
+		}
+	}
+	sort.Strings(p.Files)
+}
+
+// Write stores the pending downloads in the solution directory.
+// If nothing is pending, any previous record is removed.
+func (p *Pending) Write(dir string) error {
+	path := filepath.Join(dir, pendingFilename)
+
+	if len(p.Files) == 0 {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	// Hack because ioutil.WriteFile fails on hidden files
+	visibility.ShowFile(path)
+
+	if err := ioutil.WriteFile(path, b, os.FileMode(0600)); err != nil {
+		return err
+	}
+	return visibility.HideFile(path)
+}
@@ -0,0 +1,43 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPending(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pending")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Nothing pending yet.
+	p, err := NewPending(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, p.Files)
+
+	p.Add("b.txt", "a.txt", "b.txt")
+	assert.Equal(t, []string{"a.txt", "b.txt"}, p.Files)
+
+	err = p.Write(dir)
+	assert.NoError(t, err)
+
+	p, err = NewPending(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, p.Files)
+
+	// Writing an empty list clears the record.
+	p.Files = nil
+	err = p.Write(dir)
+	assert.NoError(t, err)
+
+	_, err = os.Lstat(filepath.Join(dir, pendingFilename))
+	assert.True(t, os.IsNotExist(err))
+
+	// Clearing twice is fine.
+	err = p.Write(dir)
+	assert.NoError(t, err)
+}
@@ -14,6 +14,10 @@ import (
 
 const solutionFilename = ".solution.json"
 
+// IncomingSuffix marks a downloaded file that was saved beside
+// a conflicting local copy. These files are never submitted.
+const IncomingSuffix = ".incoming"
+
 // Solution contains metadata about a user's solution.
 type Solution struct {
 	Track       string     `json:"track"`
@@ -79,6 +83,47 @@ func (s *Solution) Write(dir string) error {
 	return visibility.HideFile(path)
 }
 
+// Files lists the files in the solution directory that pass the given filter.
+// The filter is called with the path relative to the solution directory,
+// using forward slashes. Hidden files and directories are always skipped,
+// and so are downloads that were saved beside a local copy.
+func (s *Solution) Files(accept func(string) (bool, error)) ([]string, error) {
+	var files []string
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == s.Dir {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || strings.HasSuffix(info.Name(), IncomingSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return err
+		}
+		ok, err := accept(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		if ok {
+			files = append(files, path)
+		}
+		return nil
+	}
+	if err := filepath.Walk(s.Dir, walkFn); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
 // PathToParent is the relative path from the workspace to the parent dir.
 func (s *Solution) PathToParent() string {
 	var dir string
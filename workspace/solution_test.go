@@ -3,6 +3,8 @@ package workspace
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -146,3 +148,45 @@ func TestSolutionString(t *testing.T) {
 		})
 	}
 }
+
+func TestSolutionFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "solution-files")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := &Solution{Exercise: "bogus-exercise", Dir: dir}
+	err = s.Write(dir)
+	assert.NoError(t, err)
+
+	for _, name := range []string{
+		"one.txt",
+		"README.md",
+		filepath.Join("sub", "two.txt"),
+		filepath.Join("sub", "two_test.txt"),
+		filepath.Join(".git", "config"),
+		"one.txt" + IncomingSuffix,
+	} {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755))
+		assert.NoError(t, err)
+		err = ioutil.WriteFile(path, []byte(name), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	var seen []string
+	accept := func(path string) (bool, error) {
+		seen = append(seen, path)
+		return !strings.HasSuffix(path, ".md") && !strings.HasSuffix(path, "_test.txt"), nil
+	}
+	files, err := s.Files(accept)
+	assert.NoError(t, err)
+
+	// Paths are given to the filter relative to the solution, with forward slashes.
+	assert.Contains(t, seen, "sub/two.txt")
+
+	expected := []string{
+		filepath.Join(dir, "one.txt"),
+		filepath.Join(dir, "sub", "two.txt"),
+	}
+	assert.Equal(t, expected, files)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -16,6 +17,9 @@ var rgxSerialSuffix = regexp.MustCompile(`-\d*$`)
 // exercises that they've downloaded to look at or run locally.
 type Workspace struct {
 	Dir string
+	// Root is the whole workspace, if Dir is only a part of it,
+	// e.g. the directory of a track. The index is kept in the root.
+	Root string
 }
 
 // New returns a configured workspace.
@@ -31,6 +35,53 @@ func New(dir string) (Workspace, error) {
 	return Workspace{Dir: dir}, nil
 }
 
+// In returns the part of the workspace in a subdirectory, e.g. a track.
+// It shares the index of the whole workspace.
+func (ws Workspace) In(elem ...string) (Workspace, error) {
+	sub, err := New(filepath.Join(append([]string{ws.Dir}, elem...)...))
+	if err != nil {
+		return Workspace{}, err
+	}
+	sub.Root = ws.Root
+	if sub.Root == "" {
+		sub.Root = ws.Dir
+	}
+	return sub, nil
+}
+
+// root is the workspace directory, with any symlinks resolved.
+// Paths found in the workspace are always below the root.
+func (ws Workspace) root() string {
+	return resolve(ws.Dir)
+}
+
+// indexRoot is the directory of the whole workspace, with any symlinks resolved.
+func (ws Workspace) indexRoot() string {
+	if ws.Root == "" {
+		return ws.root()
+	}
+	return resolve(ws.Root)
+}
+
+func resolve(dir string) string {
+	if src, err := filepath.EvalSymlinks(dir); err == nil {
+		return src
+	}
+	return dir
+}
+
+// covers checks whether an indexed path belongs to this part of the workspace.
+// Symlinked exercises may point anywhere, so in the whole workspace
+// everything in the index is used.
+func (ws Workspace) covers(path string) bool {
+	return ws.Root == "" || contains(ws.root(), path)
+}
+
+// contains checks whether the path is the directory, or below it.
+func contains(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
+
 // Locate the matching directories within the workspace.
 // This will look for an exact match on absolute or relative paths.
 // If given the base name of a directory with no path information it
@@ -66,8 +117,26 @@ func (ws Workspace) Locate(exercise string) ([]string, error) {
 		return nil, ErrNotExist(exercise)
 	}
 
+	root := ws.root()
+
+	// If the workspace has an index, we don't need to walk the tree.
+	idx := ws.index()
+	if idx != nil {
+		var paths []string
+		for _, dir := range idx.Dirs {
+			path := filepath.Join(ws.indexRoot(), dir)
+			if ws.covers(path) && matchesExercise(path, exercise) {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) > 0 {
+			return paths, nil
+		}
+	}
+
+	// The index doesn't cover everything, e.g. directories inside of solutions,
+	// so look through the entire workspace tree to find any matches.
 	var paths []string
-	// Look through the entire workspace tree to find any matches.
 	walkFn := func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -89,34 +158,89 @@ func (ws Workspace) Locate(exercise string) ([]string, error) {
 			return nil
 		}
 
-		if strings.HasPrefix(filepath.Base(path), exercise) {
-			// We're trying to find any directories that match either the exact name
-			// or the name with a numeric suffix.
-			// E.g. if passed 'bat', then we should match 'bat', 'bat-2', 'bat-200',
-			// but not 'batten'.
-			suffix := strings.Replace(filepath.Base(path), exercise, "", 1)
-			if suffix == "" || rgxSerialSuffix.MatchString(suffix) {
-				paths = append(paths, path)
-			}
+		if matchesExercise(path, exercise) {
+			paths = append(paths, path)
 		}
 		return nil
 	}
 
-	// If the workspace directory is a symlink, resolve that first.
-	root := ws.Dir
-	src, err := filepath.EvalSymlinks(root)
-	if err == nil {
-		root = src
-	}
-
 	filepath.Walk(root, walkFn)
 
 	if len(paths) == 0 {
 		return nil, ErrNotExist(exercise)
 	}
+	if idx != nil {
+		// The index missed them, so bring it up to date.
+		// It's only there to speed things up, so this may fail.
+		ws.RebuildIndex()
+	}
 	return paths, nil
 }
 
+// matchesExercise checks whether the directory is named after the exercise.
+// We're trying to find any directories that match either the exact name
+// or the name with a numeric suffix.
+// E.g. if passed 'bat', then we should match 'bat', 'bat-2', 'bat-200',
+// but not 'batten'.
+func matchesExercise(path, exercise string) bool {
+	if !strings.HasPrefix(filepath.Base(path), exercise) {
+		return false
+	}
+	suffix := strings.Replace(filepath.Base(path), exercise, "", 1)
+	return suffix == "" || rgxSerialSuffix.MatchString(suffix)
+}
+
+// Solutions loads the metadata of every solution in the workspace.
+// This includes the person's own solutions as well as any solutions
+// by other people that they have downloaded.
+func (ws Workspace) Solutions() ([]*Solution, error) {
+	var solutions []*Solution
+	root := ws.root()
+
+	if idx := ws.index(); idx != nil {
+		entries := idx.Solutions
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Dir < entries[j].Dir })
+		for _, entry := range entries {
+			path := filepath.Join(ws.indexRoot(), entry.Dir)
+			if !ws.covers(path) {
+				continue
+			}
+			solution, err := NewSolution(path)
+			if err != nil {
+				return nil, err
+			}
+			solutions = append(solutions, solution)
+		}
+		return solutions, nil
+	}
+
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Lstat(filepath.Join(path, solutionFilename)); err != nil {
+			return nil
+		}
+		solution, err := NewSolution(path)
+		if err != nil {
+			return err
+		}
+		solutions = append(solutions, solution)
+		// Solutions don't nest.
+		return filepath.SkipDir
+	}
+	if err := filepath.Walk(root, walkFn); err != nil {
+		return nil, err
+	}
+	return solutions, nil
+}
+
 // SolutionPath returns the full path where the exercise will be stored.
 // By default this the directory name matches that of the exercise, but if
 // a different solution already exists, then a numeric suffix will be added
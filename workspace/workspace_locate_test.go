@@ -4,8 +4,8 @@ package workspace
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
-	"runtime"
 	"sort"
 	"testing"
 
@@ -13,8 +13,9 @@ import (
 )
 
 func TestLocateErrors(t *testing.T) {
-	_, cwd, _, _ := runtime.Caller(0)
-	root := filepath.Join(cwd, "..", "..", "fixtures", "locate-exercise")
+	root := copyFixture(t, "locate-exercise")
+	defer os.RemoveAll(filepath.Dir(root))
+	rel := relativeToCwd(t, root)
 
 	ws, err := New(filepath.Join(root, "workspace"))
 	assert.NoError(t, err)
@@ -35,12 +36,12 @@ func TestLocateErrors(t *testing.T) {
 		},
 		{
 			desc:  "relative path is outside of workspace",
-			arg:   filepath.Join("..", "fixtures", "locate-exercise", "equipment", "bat"),
+			arg:   filepath.Join(rel, "equipment", "bat"),
 			errFn: IsNotInWorkspace,
 		},
 		{
 			desc:  "relative path in workspace not found",
-			arg:   filepath.Join("..", "fixtures", "locate-exercise", "workspace", "creatures", "pig"),
+			arg:   filepath.Join(rel, "workspace", "creatures", "pig"),
 			errFn: IsNotExist,
 		},
 		{
@@ -66,8 +67,9 @@ type locateTestCase struct {
 }
 
 func TestLocate(t *testing.T) {
-	_, cwd, _, _ := runtime.Caller(0)
-	root := filepath.Join(cwd, "..", "..", "fixtures", "locate-exercise")
+	root := copyFixture(t, "locate-exercise")
+	defer os.RemoveAll(filepath.Dir(root))
+	rel := relativeToCwd(t, root)
 
 	wsPrimary, err := New(filepath.Join(root, "workspace"))
 	assert.NoError(t, err)
@@ -82,7 +84,7 @@ func TestLocate(t *testing.T) {
 		{
 			desc:      "find relative path within workspace",
 			workspace: wsPrimary,
-			in:        filepath.Join("..", "fixtures", "locate-exercise", "workspace", "creatures", "horse"),
+			in:        filepath.Join(rel, "workspace", "creatures", "horse"),
 			out:       []string{filepath.Join(wsPrimary.Dir, "creatures", "horse")},
 		},
 		{
@@ -126,6 +128,15 @@ func TestLocate(t *testing.T) {
 	testLocate(testCases, t)
 }
 
+// relativeToCwd gives the path relative to the working directory of the test.
+func relativeToCwd(t *testing.T, path string) string {
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	rel, err := filepath.Rel(cwd, path)
+	assert.NoError(t, err)
+	return rel
+}
+
 func testLocate(testCases []locateTestCase, t *testing.T) {
 	for _, tc := range testCases {
 		dirs, err := tc.workspace.Locate(tc.in)
@@ -3,16 +3,16 @@
 package workspace
 
 import (
+	"os"
 	"path/filepath"
-	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLocateSymlinks(t *testing.T) {
-	_, cwd, _, _ := runtime.Caller(0)
-	root := filepath.Join(cwd, "..", "..", "fixtures", "locate-exercise")
+	root := copyFixture(t, "locate-exercise")
+	defer os.RemoveAll(filepath.Dir(root))
 
 	wsSymbolic, err := New(filepath.Join(root, "symlinked-workspace"))
 	assert.NoError(t, err)
@@ -2,6 +2,7 @@ package workspace
 
 import (
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -9,8 +10,49 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// copyFixture copies a fixture into a temporary directory, keeping symlinks as they are.
+// Looking for exercises writes the workspace index, which must not end up in the fixtures.
+func copyFixture(t *testing.T, elem ...string) string {
+	tmpDir, err := ioutil.TempDir("", "workspace-fixture")
+	assert.NoError(t, err)
+	tmpDir, err = filepath.EvalSymlinks(tmpDir)
+	assert.NoError(t, err)
+
+	src := filepath.Join(append([]string{"..", "fixtures"}, elem...)...)
+	dst := filepath.Join(tmpDir, filepath.Base(src))
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode())
+		default:
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(target, b, info.Mode())
+		}
+	})
+	assert.NoError(t, err)
+	return dst
+}
+
 func TestSolutionPath(t *testing.T) {
-	root := filepath.Join("..", "fixtures", "solution-path", "creatures")
+	root := copyFixture(t, "solution-path", "creatures")
+	defer os.RemoveAll(filepath.Dir(root))
 	ws, err := New(root)
 	assert.NoError(t, err)
 
@@ -188,3 +230,37 @@ func TestSolutionDir(t *testing.T) {
 		assert.Equal(t, filepath.Join(ws.Dir, "exercise"), dir, test.path)
 	}
 }
+
+func TestSolutions(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "workspace-solutions")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	ws, err := New(tmpDir)
+	assert.NoError(t, err)
+
+	dirs := map[string]*Solution{
+		filepath.Join("go", "leap"):                    {ID: "aaa", Track: "go", Exercise: "leap", IsRequester: true},
+		filepath.Join("go", "leap-2"):                  {ID: "bbb", Track: "go", Exercise: "leap", IsRequester: true},
+		filepath.Join("users", "alice", "ruby", "bob"): {ID: "ccc", Track: "ruby", Exercise: "bob", Handle: "alice"},
+	}
+	for dir, solution := range dirs {
+		path := filepath.Join(ws.Dir, dir)
+		err := os.MkdirAll(path, os.FileMode(0755))
+		assert.NoError(t, err)
+		err = solution.Write(path)
+		assert.NoError(t, err)
+	}
+	// Directories without metadata are not solutions.
+	err = os.MkdirAll(filepath.Join(ws.Dir, "go", "scratch"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	solutions, err := ws.Solutions()
+	assert.NoError(t, err)
+
+	var ids []string
+	for _, solution := range solutions {
+		ids = append(ids, solution.ID)
+	}
+	assert.Equal(t, []string{"aaa", "bbb", "ccc"}, ids)
+}